@@ -0,0 +1,223 @@
+package diodes
+
+import (
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// AggregatingAlerter sums the missed counts it is alerted with and forwards
+// them to the wrapped Alerter at most once per interval.
+type AggregatingAlerter struct {
+	alerter  Alerter
+	interval time.Duration
+	clock    Clock
+
+	mu      sync.Mutex
+	pending int
+	last    time.Time
+	timer   *time.Timer
+	closed  bool
+}
+
+// NewAggregatingAlerter returns an AggregatingAlerter that forwards to the
+// given alerter. The first alert is forwarded right away, later alerts are
+// held back until the interval has passed since the last forward. A timer
+// forwards held back counts at the end of the interval, so the last count of
+// a burst is reported even when no more alerts arrive. Close stops the
+// timer. The clock is only used to check whether the interval has passed
+// when an alert arrives; the timer always runs on wall time. A nil clock
+// uses the system clock.
+func NewAggregatingAlerter(alerter Alerter, interval time.Duration, clock Clock) *AggregatingAlerter {
+	if clock == nil {
+		clock = systemClock{}
+	}
+
+	return &AggregatingAlerter{
+		alerter:  alerter,
+		interval: interval,
+		clock:    clock,
+	}
+}
+
+// Alert adds missed to the pending count and forwards it if the interval
+// has passed.
+func (a *AggregatingAlerter) Alert(missed int) {
+	a.mu.Lock()
+	a.pending += missed
+
+	now := a.clock.Now()
+	if !a.closed && !a.last.IsZero() && now.Sub(a.last) < a.interval {
+		if a.timer == nil {
+			a.timer = time.AfterFunc(a.interval-now.Sub(a.last), a.expire)
+		}
+		a.mu.Unlock()
+		return
+	}
+
+	a.stopTimer()
+	pending := a.pending
+	a.pending = 0
+	a.last = now
+	a.mu.Unlock()
+
+	a.alerter.Alert(pending)
+}
+
+// Flush forwards the pending count, if any, regardless of the interval.
+func (a *AggregatingAlerter) Flush() {
+	a.mu.Lock()
+	a.stopTimer()
+	pending := a.pending
+	a.pending = 0
+	a.mu.Unlock()
+
+	if pending > 0 {
+		a.alerter.Alert(pending)
+	}
+}
+
+// Close forwards the pending count, if any, and stops the timer. Alerts
+// after Close are forwarded right away.
+func (a *AggregatingAlerter) Close() {
+	a.mu.Lock()
+	a.closed = true
+	a.mu.Unlock()
+
+	a.Flush()
+}
+
+// expire is invoked by the timer at the end of the interval in which alerts
+// were held back.
+func (a *AggregatingAlerter) expire() {
+	a.mu.Lock()
+	a.timer = nil
+	pending := a.pending
+	a.pending = 0
+	if pending > 0 {
+		a.last = a.clock.Now()
+	}
+	a.mu.Unlock()
+
+	if pending > 0 {
+		a.alerter.Alert(pending)
+	}
+}
+
+// stopTimer stops the timer, if any. The caller must hold mu.
+func (a *AggregatingAlerter) stopTimer() {
+	if a.timer != nil {
+		a.timer.Stop()
+		a.timer = nil
+	}
+}
+
+// SamplingAlerter forwards every nth alert to the wrapped Alerter. The
+// missed counts of the alerts in between are not lost: they are added to the
+// next forwarded alert.
+type SamplingAlerter struct {
+	alerter Alerter
+	n       int
+
+	mu      sync.Mutex
+	calls   int
+	pending int
+}
+
+// NewSamplingAlerter returns a SamplingAlerter that forwards every nth alert
+// to the given alerter, starting with the first one.
+func NewSamplingAlerter(alerter Alerter, n int) *SamplingAlerter {
+	if n < 1 {
+		n = 1
+	}
+
+	return &SamplingAlerter{
+		alerter: alerter,
+		n:       n,
+	}
+}
+
+// Alert adds missed to the pending count and forwards it if this is a
+// sampled alert.
+func (a *SamplingAlerter) Alert(missed int) {
+	a.mu.Lock()
+	a.pending += missed
+	a.calls++
+	if (a.calls-1)%a.n != 0 {
+		a.mu.Unlock()
+		return
+	}
+
+	pending := a.pending
+	a.pending = 0
+	a.mu.Unlock()
+
+	a.alerter.Alert(pending)
+}
+
+// AsyncAlerter forwards alerts to the wrapped Alerter on its own go-routine
+// so that slow alert handling does not slow down the reader of a diode.
+// Alerts that arrive while the wrapped Alerter is busy are summed and
+// forwarded together. Alert never blocks.
+type AsyncAlerter struct {
+	pending int64
+	alerter Alerter
+	signal  chan struct{}
+	done    chan struct{}
+	closed  sync.Once
+	stopped chan struct{}
+}
+
+// NewAsyncAlerter returns an AsyncAlerter that forwards to the given alerter.
+// It starts a go-routine that runs until Close is invoked.
+func NewAsyncAlerter(alerter Alerter) *AsyncAlerter {
+	a := &AsyncAlerter{
+		alerter: alerter,
+		signal:  make(chan struct{}, 1),
+		done:    make(chan struct{}),
+		stopped: make(chan struct{}),
+	}
+	go a.run()
+
+	return a
+}
+
+// Alert adds missed to the pending count and wakes up the forwarding
+// go-routine.
+func (a *AsyncAlerter) Alert(missed int) {
+	atomic.AddInt64(&a.pending, int64(missed))
+
+	select {
+	case a.signal <- struct{}{}:
+	default:
+	}
+}
+
+// Close forwards any pending count and stops the forwarding go-routine. It
+// waits for the go-routine to exit.
+func (a *AsyncAlerter) Close() {
+	a.closed.Do(func() {
+		close(a.done)
+	})
+	<-a.stopped
+}
+
+func (a *AsyncAlerter) run() {
+	defer close(a.stopped)
+
+	for {
+		select {
+		case <-a.signal:
+			a.forward()
+		case <-a.done:
+			a.forward()
+			return
+		}
+	}
+}
+
+func (a *AsyncAlerter) forward() {
+	if pending := atomic.SwapInt64(&a.pending, 0); pending > 0 {
+		a.alerter.Alert(int(pending))
+	}
+}
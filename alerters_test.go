@@ -0,0 +1,178 @@
+package diodes_test
+
+import (
+	"sync"
+	"time"
+
+	"code.cloudfoundry.org/go-diodes"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("AggregatingAlerter", func() {
+	var (
+		spy   *spyAlerter
+		clock *fakeClock
+		a     *diodes.AggregatingAlerter
+	)
+
+	BeforeEach(func() {
+		spy = newSpyAlerter()
+		clock = newFakeClock()
+		// The timer runs on wall time, so the interval is long enough that
+		// it never fires while a spec advances the fake clock.
+		a = diodes.NewAggregatingAlerter(spy, time.Hour, clock)
+	})
+
+	AfterEach(func() {
+		a.Close()
+	})
+
+	It("forwards the first alert right away", func() {
+		a.Alert(3)
+		Expect(spy.AlertInput.Missed).To(Receive(Equal(3)))
+	})
+
+	It("forwards at most once per interval", func() {
+		a.Alert(1)
+		Expect(spy.AlertInput.Missed).To(Receive(Equal(1)))
+
+		a.Alert(2)
+		clock.Advance(30 * time.Minute)
+		a.Alert(3)
+		Expect(spy.AlertInput.Missed).ToNot(Receive())
+
+		clock.Advance(30 * time.Minute)
+		a.Alert(4)
+		Expect(spy.AlertInput.Missed).To(Receive(Equal(9)))
+	})
+
+	It("flushes the pending count", func() {
+		a.Alert(1)
+		<-spy.AlertInput.Missed
+		a.Alert(2)
+		a.Flush()
+
+		Expect(spy.AlertInput.Missed).To(Receive(Equal(2)))
+	})
+
+	It("forwards the held back count at the end of the interval", func() {
+		a = diodes.NewAggregatingAlerter(spy, 10*time.Millisecond, nil)
+		a.Alert(1)
+		<-spy.AlertInput.Missed
+		a.Alert(2)
+		a.Alert(3)
+
+		Eventually(spy.AlertInput.Missed).Should(Receive(Equal(5)))
+		Consistently(spy.AlertInput.Missed, 50*time.Millisecond).ShouldNot(Receive())
+	})
+
+	It("forwards the pending count and alerts right away once closed", func() {
+		a.Alert(1)
+		<-spy.AlertInput.Missed
+		a.Alert(2)
+		a.Close()
+		Expect(spy.AlertInput.Missed).To(Receive(Equal(2)))
+
+		a.Alert(3)
+		Expect(spy.AlertInput.Missed).To(Receive(Equal(3)))
+	})
+
+	It("does not flush without a pending count", func() {
+		a.Flush()
+		Expect(spy.AlertCalled).ToNot(Receive())
+	})
+
+	It("can be used as the alerter of a diode", func() {
+		d := diodes.NewOneToOne(2, a)
+		data := 1
+		for i := 0; i < 5; i++ {
+			d.Set(diodes.GenericDataType(&data))
+		}
+		d.TryNext()
+
+		Expect(spy.AlertInput.Missed).To(Receive(Equal(4)))
+	})
+})
+
+var _ = Describe("SamplingAlerter", func() {
+	It("forwards every nth alert with the skipped counts added", func() {
+		spy := newSpyAlerter()
+		a := diodes.NewSamplingAlerter(spy, 3)
+
+		for i := 1; i <= 7; i++ {
+			a.Alert(i)
+		}
+
+		Expect(spy.AlertInput.Missed).To(Receive(Equal(1)))
+		Expect(spy.AlertInput.Missed).To(Receive(Equal(2 + 3 + 4)))
+		Expect(spy.AlertInput.Missed).To(Receive(Equal(5 + 6 + 7)))
+		Expect(spy.AlertInput.Missed).ToNot(Receive())
+	})
+
+	It("forwards every alert when n is less than 1", func() {
+		spy := newSpyAlerter()
+		a := diodes.NewSamplingAlerter(spy, 0)
+
+		a.Alert(1)
+		a.Alert(2)
+
+		Expect(spy.AlertInput.Missed).To(Receive(Equal(1)))
+		Expect(spy.AlertInput.Missed).To(Receive(Equal(2)))
+	})
+})
+
+var _ = Describe("AsyncAlerter", func() {
+	It("forwards alerts on another go-routine", func() {
+		release := make(chan struct{})
+		var (
+			mu    sync.Mutex
+			total int
+		)
+		a := diodes.NewAsyncAlerter(diodes.AlertFunc(func(missed int) {
+			<-release
+			mu.Lock()
+			defer mu.Unlock()
+			total += missed
+		}))
+		defer a.Close()
+
+		done := make(chan struct{})
+		go func() {
+			defer close(done)
+			for i := 0; i < 10; i++ {
+				a.Alert(1)
+			}
+		}()
+		Eventually(done).Should(BeClosed())
+
+		close(release)
+		Eventually(func() int {
+			mu.Lock()
+			defer mu.Unlock()
+			return total
+		}).Should(Equal(10))
+	})
+
+	It("forwards the pending count when it is closed", func() {
+		spy := newSpyAlerter()
+		a := diodes.NewAsyncAlerter(spy)
+		a.Alert(2)
+		a.Alert(3)
+		a.Close()
+
+		var total int
+		for len(spy.AlertInput.Missed) > 0 {
+			total += <-spy.AlertInput.Missed
+		}
+		Expect(total).To(Equal(5))
+	})
+
+	It("can be closed more than once", func() {
+		a := diodes.NewAsyncAlerter(newSpyAlerter())
+		a.Close()
+
+		Expect(a.Close).ToNot(Panic())
+	})
+})
@@ -0,0 +1,172 @@
+package diodes
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// ResizeHandler is used to report that an AutoSizer resized its diode.
+type ResizeHandler interface {
+	Resized(oldSize, newSize int)
+}
+
+// ResizeFunc type is an adapter to allow the use of ordinary functions as
+// ResizeHandlers.
+type ResizeFunc func(oldSize, newSize int)
+
+// Resized calls f(oldSize, newSize)
+func (f ResizeFunc) Resized(oldSize, newSize int) {
+	f(oldSize, newSize)
+}
+
+// AutoSizer resizes a Resizable diode based on its Stats. At every interval
+// it doubles the size, up to a max, when the share of writes that were
+// dropped during the interval exceeds the grow threshold. It halves the size,
+// down to the size the diode started with, when the diode stayed below the
+// shrink occupancy for several intervals in a row.
+type AutoSizer struct {
+	d       *Resizable
+	minSize int
+	maxSize int
+	handler ResizeHandler
+
+	interval        time.Duration
+	ctx             context.Context
+	growThreshold   float64
+	shrinkOccupancy float64
+	shrinkAfter     int
+
+	mu      sync.Mutex
+	writes  uint64
+	dropped uint64
+	low     int
+}
+
+// AutoSizerConfigOption can be used to setup the auto sizer.
+type AutoSizerConfigOption func(*AutoSizer)
+
+// WithAutoSizerInterval sets the interval at which the diode's Stats are
+// checked. It is also the window the drop rate is measured over. The default
+// is 1 second.
+func WithAutoSizerInterval(interval time.Duration) AutoSizerConfigOption {
+	return AutoSizerConfigOption(func(a *AutoSizer) {
+		a.interval = interval
+	})
+}
+
+// WithAutoSizerContext sets the context that stops Run. Default is
+// context.Background().
+func WithAutoSizerContext(ctx context.Context) AutoSizerConfigOption {
+	return AutoSizerConfigOption(func(a *AutoSizer) {
+		a.ctx = ctx
+	})
+}
+
+// WithGrowThreshold sets the share of writes (between 0 and 1) that may be
+// dropped during an interval before the diode grows. The default is 0.01.
+func WithGrowThreshold(threshold float64) AutoSizerConfigOption {
+	return AutoSizerConfigOption(func(a *AutoSizer) {
+		a.growThreshold = threshold
+	})
+}
+
+// WithShrinkOccupancy sets the share of the capacity (between 0 and 1) the
+// diode has to stay below for it to shrink, and the number of intervals in a
+// row it has to stay below it. The default is 0.25 for 5 intervals.
+func WithShrinkOccupancy(occupancy float64, intervals int) AutoSizerConfigOption {
+	return AutoSizerConfigOption(func(a *AutoSizer) {
+		a.shrinkOccupancy = occupancy
+		a.shrinkAfter = intervals
+	})
+}
+
+// NewAutoSizer returns a new AutoSizer for the given diode. The diode never
+// grows beyond maxSize, rounded up to the next power of two, and never
+// shrinks below the size it has now. The handler is invoked after every
+// resize. A nil can be used to ignore resizes.
+func NewAutoSizer(d *Resizable, maxSize int, handler ResizeHandler, opts ...AutoSizerConfigOption) *AutoSizer {
+	if handler == nil {
+		handler = ResizeFunc(func(int, int) {})
+	}
+
+	a := &AutoSizer{
+		d:               d,
+		minSize:         d.Cap(),
+		maxSize:         maxSize,
+		handler:         handler,
+		interval:        time.Second,
+		ctx:             context.Background(),
+		growThreshold:   0.01,
+		shrinkOccupancy: 0.25,
+		shrinkAfter:     5,
+	}
+
+	for _, o := range opts {
+		o(a)
+	}
+
+	s := d.Stats()
+	a.writes = s.Writes
+	a.dropped = s.Dropped
+	return a
+}
+
+// Run checks the diode at every interval until the context is done. It is
+// meant to be invoked on its own go-routine.
+func (a *AutoSizer) Run() {
+	t := time.NewTicker(a.interval)
+	defer t.Stop()
+
+	for {
+		select {
+		case <-a.ctx.Done():
+			return
+		case <-t.C:
+			a.Check()
+		}
+	}
+}
+
+// Check compares the diode's Stats with the previous check and resizes the
+// diode if needed. Run invokes it at every interval.
+func (a *AutoSizer) Check() {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	s := a.d.Stats()
+	writes := s.Writes - a.writes
+	dropped := s.Dropped - a.dropped
+	a.writes = s.Writes
+	a.dropped = s.Dropped
+
+	size := a.d.Cap()
+	if writes > 0 && float64(dropped)/float64(writes) > a.growThreshold {
+		a.low = 0
+		a.resize(size, min(size*2, a.maxSize))
+		return
+	}
+
+	if float64(s.Len) >= a.shrinkOccupancy*float64(size) {
+		a.low = 0
+		return
+	}
+
+	a.low++
+	if a.low < a.shrinkAfter {
+		return
+	}
+
+	a.low = 0
+	a.resize(size, max(size/2, a.minSize))
+}
+
+func (a *AutoSizer) resize(oldSize, newSize int) {
+	newSize = ringSize(newSize)
+	if newSize == oldSize {
+		return
+	}
+
+	a.d.Resize(newSize)
+	a.handler.Resized(oldSize, newSize)
+}
@@ -0,0 +1,133 @@
+package diodes_test
+
+import (
+	"context"
+	"time"
+
+	"code.cloudfoundry.org/go-diodes"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("AutoSizer", func() {
+	type resize struct {
+		oldSize, newSize int
+	}
+
+	var (
+		resizes []resize
+		d       *diodes.Resizable
+		a       *diodes.AutoSizer
+	)
+
+	set := func(n int) {
+		data := 1
+		for i := 0; i < n; i++ {
+			d.Set(diodes.GenericDataType(&data))
+		}
+	}
+
+	drain := func() {
+		for {
+			if _, ok := d.TryNext(); !ok {
+				return
+			}
+		}
+	}
+
+	BeforeEach(func() {
+		resizes = nil
+		d = diodes.NewResizable(4, nil)
+		a = diodes.NewAutoSizer(d, 16, diodes.ResizeFunc(func(oldSize, newSize int) {
+			resizes = append(resizes, resize{oldSize, newSize})
+		}), diodes.WithGrowThreshold(0.1), diodes.WithShrinkOccupancy(0.25, 2))
+	})
+
+	It("grows when the drop rate exceeds the threshold", func() {
+		set(10)
+		drain()
+		a.Check()
+
+		Expect(d.Cap()).To(Equal(8))
+		Expect(resizes).To(Equal([]resize{{4, 8}}))
+	})
+
+	It("does not grow beyond the max", func() {
+		for i := 0; i < 4; i++ {
+			set(40)
+			drain()
+			a.Check()
+		}
+
+		Expect(d.Cap()).To(Equal(16))
+		Expect(resizes).To(Equal([]resize{{4, 8}, {8, 16}}))
+	})
+
+	It("does not grow when the drop rate is below the threshold", func() {
+		for i := 0; i < 5; i++ {
+			set(3)
+			drain()
+		}
+		a.Check()
+
+		Expect(d.Cap()).To(Equal(4))
+		Expect(resizes).To(BeEmpty())
+	})
+
+	It("shrinks after sustained low occupancy", func() {
+		set(10)
+		drain()
+		a.Check()
+		Expect(d.Cap()).To(Equal(8))
+
+		a.Check()
+		Expect(d.Cap()).To(Equal(8))
+		a.Check()
+		Expect(d.Cap()).To(Equal(4))
+		Expect(resizes).To(Equal([]resize{{4, 8}, {8, 4}}))
+	})
+
+	It("does not shrink while the occupancy is high", func() {
+		set(10)
+		drain()
+		a.Check()
+
+		set(4)
+		a.Check()
+		a.Check()
+		a.Check()
+
+		Expect(d.Cap()).To(Equal(8))
+	})
+
+	It("does not shrink below the initial size", func() {
+		for i := 0; i < 4; i++ {
+			a.Check()
+		}
+
+		Expect(resizes).To(BeEmpty())
+	})
+
+	It("checks at every interval until the context is done", func() {
+		ctx, cancel := context.WithCancel(context.Background())
+		resized := make(chan resize, 10)
+		a = diodes.NewAutoSizer(d, 16, diodes.ResizeFunc(func(oldSize, newSize int) {
+			resized <- resize{oldSize, newSize}
+		}), diodes.WithAutoSizerInterval(time.Millisecond), diodes.WithAutoSizerContext(ctx))
+
+		set(10)
+		drain()
+
+		done := make(chan struct{})
+		go func() {
+			defer close(done)
+			a.Run()
+		}()
+
+		Eventually(resized).Should(Receive(Equal(resize{4, 8})))
+
+		cancel()
+		Eventually(done).Should(BeClosed())
+	})
+})
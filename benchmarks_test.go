@@ -5,6 +5,7 @@ import (
 	"io"
 	"log"
 	"os"
+	"runtime"
 	"sync"
 	"testing"
 	"time"
@@ -237,6 +238,34 @@ func BenchmarkManyWritersDiode(b *testing.B) {
 	})
 }
 
+func BenchmarkManyWritersShardedDiode(b *testing.B) {
+	d := diodes.NewWaiter(diodes.NewShardedManyToOne(runtime.GOMAXPROCS(0), 10000, diodes.AlertFunc(func(int) {
+		// NOP
+	})))
+
+	var wg sync.WaitGroup
+	wg.Add(1)
+
+	go func() {
+		wg.Done()
+		for {
+			d.Next()
+			time.Sleep(100 * time.Millisecond)
+		}
+	}()
+
+	wg.Wait()
+	b.ResetTimer()
+	b.RunParallel(func(pb *testing.PB) {
+		var i int
+		for pb.Next() {
+			data := randData(i)
+			i++
+			d.Set(diodes.GenericDataType(data))
+		}
+	})
+}
+
 func BenchmarkManyWritersChannel(b *testing.B) {
 	c := make(chan []byte, 10000)
 
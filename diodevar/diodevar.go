@@ -0,0 +1,80 @@
+// Package diodevar publishes the Stats of diodes through expvar. It is kept
+// out of the diodes package since importing expvar registers the
+// /debug/vars handler on http.DefaultServeMux.
+package diodevar
+
+import (
+	"expvar"
+
+	"code.cloudfoundry.org/go-diodes"
+)
+
+// Publish publishes the Stats of the given diode as an expvar with the given
+// name. The Stats are read every time the variable is rendered. Diodes that
+// do not implement diodes.StatsReporter are published with zero values. Like
+// expvar.Publish, it panics if the name is already in use.
+func Publish(name string, d diodes.Diode) {
+	expvar.Publish(name, Func(d))
+}
+
+// Func returns an expvar.Func that renders the Stats of the given diode.
+func Func(d diodes.Diode) expvar.Func {
+	return expvar.Func(func() any {
+		var s diodes.Stats
+		if r, ok := d.(diodes.StatsReporter); ok {
+			s = r.Stats()
+		}
+
+		return newVars(s)
+	})
+}
+
+type vars struct {
+	Capacity    int          `json:"capacity"`
+	Len         int          `json:"len"`
+	Writes      uint64       `json:"writes"`
+	Reads       uint64       `json:"reads"`
+	Dropped     uint64       `json:"dropped"`
+	Reset       uint64       `json:"reset"`
+	Expired     uint64       `json:"expired"`
+	Overwritten uint64       `json:"overwritten"`
+	Collisions  uint64       `json:"collisions"`
+	Latency     *latencyVars `json:"latency,omitempty"`
+
+	TagDrops map[diodes.Tag]uint64 `json:"tag_drops,omitempty"`
+}
+
+type latencyVars struct {
+	Count  uint64 `json:"count"`
+	MeanNs int64  `json:"mean_ns"`
+	P50Ns  int64  `json:"p50_ns"`
+	P90Ns  int64  `json:"p90_ns"`
+	P99Ns  int64  `json:"p99_ns"`
+}
+
+func newVars(s diodes.Stats) vars {
+	v := vars{
+		Capacity:    s.Capacity,
+		Len:         s.Len,
+		Writes:      s.Writes,
+		Reads:       s.Reads,
+		Dropped:     s.Dropped,
+		Reset:       s.Reset,
+		Expired:     s.Expired,
+		Overwritten: s.Overwritten,
+		Collisions:  s.Collisions,
+		TagDrops:    s.TagDrops,
+	}
+
+	if s.Latency.Count > 0 {
+		v.Latency = &latencyVars{
+			Count:  s.Latency.Count,
+			MeanNs: int64(s.Latency.Mean()),
+			P50Ns:  int64(s.Latency.Quantile(0.5)),
+			P90Ns:  int64(s.Latency.Quantile(0.9)),
+			P99Ns:  int64(s.Latency.Quantile(0.99)),
+		}
+	}
+
+	return v
+}
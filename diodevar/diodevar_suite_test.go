@@ -0,0 +1,13 @@
+package diodevar_test
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"testing"
+)
+
+func TestDiodevar(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Diodevar Suite")
+}
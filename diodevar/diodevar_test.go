@@ -0,0 +1,102 @@
+package diodevar_test
+
+import (
+	"encoding/json"
+	"expvar"
+	"fmt"
+	"sync/atomic"
+
+	"code.cloudfoundry.org/go-diodes"
+	"code.cloudfoundry.org/go-diodes/diodevar"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Publish", func() {
+	render := func(name string) map[string]any {
+		v := expvar.Get(name)
+		Expect(v).ToNot(BeNil())
+
+		var m map[string]any
+		Expect(json.Unmarshal([]byte(v.String()), &m)).To(Succeed())
+		return m
+	}
+
+	It("publishes the stats of a diode", func() {
+		d := diodes.NewOneToOne(4, nil)
+		name := uniqueName("storage")
+		diodevar.Publish(name, d)
+
+		data := 1
+		for i := 0; i < 6; i++ {
+			d.Set(diodes.GenericDataType(&data))
+		}
+		d.TryNext()
+
+		Expect(render(name)).To(Equal(map[string]any{
+			"capacity":    4.0,
+			"len":         1.0,
+			"writes":      6.0,
+			"reads":       1.0,
+			"dropped":     4.0,
+			"reset":       0.0,
+			"expired":     0.0,
+			"overwritten": 0.0,
+			"collisions":  0.0,
+		}))
+	})
+
+	It("publishes the stats of the diode wrapped by a Waiter", func() {
+		d := diodes.NewManyToOne(4, nil, diodes.WithLatencyHistogram())
+		w := diodes.NewWaiter(d)
+		name := uniqueName("waiter")
+		diodevar.Publish(name, w)
+
+		data := 1
+		w.Set(diodes.GenericDataType(&data))
+		w.Next()
+
+		m := render(name)
+		Expect(m).To(HaveKeyWithValue("reads", 1.0))
+		Expect(m).To(HaveKey("latency"))
+		Expect(m["latency"]).To(HaveKeyWithValue("count", 1.0))
+	})
+
+	It("publishes the drops per tag", func() {
+		d := diodes.NewManyToOne(2, nil, diodes.WithTagDrops())
+		name := uniqueName("tags")
+		diodevar.Publish(name, d)
+
+		data := 1
+		for i := 0; i < 3; i++ {
+			d.SetTagged(7, diodes.GenericDataType(&data))
+		}
+
+		Expect(render(name)).To(HaveKeyWithValue("tag_drops", map[string]any{"7": 1.0}))
+	})
+
+	It("publishes zero values for diodes without stats", func() {
+		name := uniqueName("latest")
+		diodevar.Publish(name, diodes.NewLatest(nil))
+
+		Expect(render(name)).To(HaveKeyWithValue("writes", 0.0))
+	})
+
+	It("panics when the name is already in use", func() {
+		name := uniqueName("duplicate")
+		diodevar.Publish(name, diodes.NewLatest(nil))
+
+		Expect(func() {
+			diodevar.Publish(name, diodes.NewLatest(nil))
+		}).To(Panic())
+	})
+})
+
+var published uint64
+
+// uniqueName returns a new expvar name since expvar does not allow names to
+// be published twice in a process.
+func uniqueName(prefix string) string {
+	return fmt.Sprintf("diodevar-%s-%d", prefix, atomic.AddUint64(&published, 1))
+}
@@ -0,0 +1,15 @@
+package diodes
+
+// SetOneToOneIndex moves the read and write indexes of an empty diode to the
+// given index so tests can cover indexes that wrap around.
+func SetOneToOneIndex(d *OneToOne, index uint64) {
+	d.writeIndex = index
+	d.readIndex.Store(index)
+}
+
+// SetManyToOneIndex moves the read and write indexes of an empty diode to
+// the given index so tests can cover indexes that wrap around.
+func SetManyToOneIndex(d *ManyToOne, index uint64) {
+	d.writeIndex = index - 1
+	d.readIndex.Store(index)
+}
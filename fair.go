@@ -0,0 +1,173 @@
+package diodes
+
+import (
+	"sync"
+	"sync/atomic"
+)
+
+// ProducerAlerter is an Alerter that is also told which producer of a
+// FairDiode dropped values. When the alerter given to a FairDiode implements
+// ProducerAlerter, AlertProducer is invoked instead of Alert.
+type ProducerAlerter interface {
+	Alerter
+	AlertProducer(name string, missed int)
+}
+
+// ProducerAlertFunc type is an adapter to allow the use of ordinary
+// functions as ProducerAlerters.
+type ProducerAlertFunc func(name string, missed int)
+
+// Alert calls f("", missed)
+func (f ProducerAlertFunc) Alert(missed int) {
+	f("", missed)
+}
+
+// AlertProducer calls f(name, missed)
+func (f ProducerAlertFunc) AlertProducer(name string, missed int) {
+	f(name, missed)
+}
+
+// FairDiode isolates its producers from each other. Every registered
+// producer gets a quota: a ring buffer of its own that only it writes to and
+// that is never overwritten. Writes beyond a producer's quota go to a shared
+// overflow ring. Only the overflow ring drops data, so a chatty producer can
+// only evict its own and other producers' excess writes, never the writes
+// within a quota. The reader reads the producers and the overflow ring
+// round-robin. It is not thread safe for multiple readers.
+type FairDiode struct {
+	mu        sync.Mutex
+	producers atomic.Pointer[[]*FairProducer]
+
+	overflow *ManyToOne
+	alerter  Alerter
+	opts     []DiodeConfigOption
+	next     int
+}
+
+// FairProducer is a handle for writing to a FairDiode. It must not be used
+// by more than one go-routine at a time.
+type FairProducer struct {
+	name     string
+	quota    uint64
+	own      *OneToOne
+	overflow *Producer
+}
+
+// NewFairDiode creates a new diode with a shared overflow ring of the given
+// size. The alerter is invoked on the read's go-routine when it notices that
+// values of a producer were dropped. A nil can be used to ignore alerts. The
+// options are passed to every ring.
+func NewFairDiode(overflow int, alerter Alerter, opts ...DiodeConfigOption) *FairDiode {
+	if alerter == nil {
+		alerter = AlertFunc(func(int) {})
+	}
+
+	d := &FairDiode{
+		overflow: NewManyToOne(overflow, nil, opts...),
+		alerter:  alerter,
+		opts:     opts,
+	}
+	d.producers.Store(new([]*FairProducer))
+
+	return d
+}
+
+// Register adds a producer with the given name and quota and returns its
+// handle. Values the producer writes while it has less than quota unread
+// values in the diode are never dropped. It is safe to invoke concurrently
+// with reads and writes.
+func (d *FairDiode) Register(name string, quota int) *FairProducer {
+	p := &FairProducer{
+		name:     name,
+		quota:    uint64(quota), // nolint:gosec
+		overflow: d.overflow.Producer(),
+	}
+	if quota > 0 {
+		p.own = NewOneToOne(quota, nil, d.opts...)
+	}
+	p.overflow.alert = func(missed uint64) {
+		d.alert(name, missed)
+	}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	old := *d.producers.Load()
+	producers := make([]*FairProducer, len(old), len(old)+1)
+	copy(producers, old)
+	producers = append(producers, p)
+	d.producers.Store(&producers)
+
+	return p
+}
+
+// TryNext will attempt to read from the ring after the one that was read
+// last, moving on to the next ring until it finds data. If there is no data
+// available in any ring, it will return (nil, false).
+func (d *FairDiode) TryNext() (data GenericDataType, ok bool) {
+	producers := *d.producers.Load()
+	rings := len(producers) + 1
+
+	for i := 0; i < rings; i++ {
+		r := (d.next + i) % rings
+		if r == len(producers) {
+			data, ok = d.overflow.TryNext()
+		} else if producers[r].own != nil {
+			data, ok = producers[r].own.TryNext()
+		}
+
+		if ok {
+			d.next = r + 1
+			return data, true
+		}
+	}
+
+	return nil, false
+}
+
+// Stats returns the sum of the Stats of all rings. It is safe to call from
+// any go-routine.
+func (d *FairDiode) Stats() Stats {
+	s := d.overflow.Stats()
+	for _, p := range *d.producers.Load() {
+		if p.own == nil {
+			continue
+		}
+
+		addStats(&s, p.own.Stats())
+	}
+
+	return s
+}
+
+func (d *FairDiode) alert(name string, missed uint64) {
+	if a, ok := d.alerter.(ProducerAlerter); ok {
+		a.AlertProducer(name, int(missed)) // nolint:gosec
+		return
+	}
+
+	d.alerter.Alert(int(missed)) // nolint:gosec
+}
+
+// Name returns the name the producer was registered with.
+func (p *FairProducer) Name() string {
+	return p.name
+}
+
+// Set sets the data in the producer's own ring if it is below its quota, and
+// in the shared overflow ring otherwise. Values that go to the overflow ring
+// may be read before values that were written earlier.
+func (p *FairProducer) Set(data GenericDataType) {
+	if p.own != nil && p.own.writeIndex-p.own.readIndex.Load() < p.quota {
+		p.own.Set(data)
+		return
+	}
+
+	p.overflow.Set(data)
+}
+
+// Dropped returns the number of the producer's values that were dropped
+// from the overflow ring. It is safe to call from any go-routine.
+func (p *FairProducer) Dropped() uint64 {
+	return p.overflow.Dropped()
+}
@@ -0,0 +1,111 @@
+package diodes_test
+
+import (
+	"code.cloudfoundry.org/go-diodes"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("FairDiode", func() {
+	var (
+		alerts map[string]int
+		d      *diodes.FairDiode
+	)
+
+	set := func(p *diodes.FairProducer, values ...string) {
+		for _, v := range values {
+			p.Set(diodes.GenericDataType(&v))
+		}
+	}
+
+	readAll := func() []string {
+		var read []string
+		for {
+			data, ok := d.TryNext()
+			if !ok {
+				return read
+			}
+			read = append(read, *(*string)(data))
+		}
+	}
+
+	BeforeEach(func() {
+		alerts = make(map[string]int)
+		d = diodes.NewFairDiode(2, diodes.ProducerAlertFunc(func(name string, missed int) {
+			alerts[name] += missed
+		}))
+	})
+
+	It("reads what was written", func() {
+		p := d.Register("app", 2)
+		Expect(p.Name()).To(Equal("app"))
+
+		set(p, "a", "b")
+
+		Expect(readAll()).To(Equal([]string{"a", "b"}))
+	})
+
+	It("never drops the writes within a quota", func() {
+		quiet := d.Register("quiet", 2)
+		noisy := d.Register("noisy", 2)
+
+		set(quiet, "q1", "q2")
+		set(noisy, "n1", "n2", "n3", "n4", "n5", "n6", "n7")
+
+		Expect(readAll()).To(ConsistOf("q1", "q2", "n1", "n2", "n7"))
+	})
+
+	It("reads the producers round-robin", func() {
+		a := d.Register("a", 2)
+		b := d.Register("b", 2)
+
+		set(a, "a1", "a2")
+		set(b, "b1", "b2")
+
+		Expect(readAll()).To(Equal([]string{"a1", "b1", "a2", "b2"}))
+	})
+
+	It("frees the quota when values are read", func() {
+		p := d.Register("app", 1)
+		set(p, "a")
+		Expect(readAll()).To(Equal([]string{"a"}))
+
+		set(p, "b", "c")
+		Expect(readAll()).To(ConsistOf("b", "c"))
+		Expect(d.Stats().Writes).To(Equal(uint64(3)))
+	})
+
+	It("attributes drops to the producer", func() {
+		quiet := d.Register("quiet", 2)
+		noisy := d.Register("noisy", 0)
+
+		set(quiet, "q1", "q2")
+		set(noisy, "n1", "n2", "n3", "n4")
+		readAll()
+
+		Expect(noisy.Dropped()).To(Equal(uint64(2)))
+		Expect(quiet.Dropped()).To(BeZero())
+		Expect(alerts).To(Equal(map[string]int{"noisy": 2}))
+	})
+
+	It("attributes drops to a producer whose overflow values were all evicted", func() {
+		quiet := d.Register("quiet", 1)
+		noisy := d.Register("noisy", 0)
+
+		set(quiet, "q1", "q2")
+		set(noisy, "n1", "n2", "n3", "n4")
+		Expect(quiet.Dropped()).To(Equal(uint64(1)))
+
+		Expect(readAll()).To(ConsistOf("q1", "n4"))
+		Expect(noisy.Dropped()).To(Equal(uint64(3)))
+		Expect(alerts).To(Equal(map[string]int{"quiet": 1, "noisy": 3}))
+	})
+
+	It("sums the Stats of all rings", func() {
+		d.Register("a", 4)
+		d.Register("b", 0)
+
+		Expect(d.Stats().Capacity).To(Equal(6))
+	})
+})
@@ -0,0 +1,165 @@
+package diodes
+
+import (
+	"math"
+	"math/bits"
+	"sync/atomic"
+	"time"
+)
+
+const (
+	// histogramSubBits is the number of bits used to split each power of two
+	// into linear sub-buckets. 3 bits gives 8 sub-buckets per power of two
+	// and a relative error of at most 12.5%.
+	histogramSubBits = 3
+	histogramSub     = 1 << histogramSubBits
+
+	// histogramMaxExp is the highest power of two (in nanoseconds) that
+	// gets its own buckets. 2^43ns is a little over 2 hours, anything above
+	// that is counted in the last bucket.
+	histogramMaxExp = 43
+
+	histogramBuckets = (histogramMaxExp-histogramSubBits+2)*histogramSub + 1
+)
+
+// histogram is a lock-free histogram of durations with log-linear buckets.
+// Durations below histogramSub nanoseconds get a bucket each, then each
+// power of two is split into histogramSub linear sub-buckets.
+type histogram struct {
+	sum    atomic.Uint64
+	counts [histogramBuckets]atomic.Uint64
+}
+
+func (h *histogram) record(d time.Duration) {
+	if d < 0 {
+		d = 0
+	}
+
+	h.counts[histogramIndex(uint64(d))].Add(1)
+	h.sum.Add(uint64(d))
+}
+
+func (h *histogram) snapshot() HistogramSnapshot {
+	var s HistogramSnapshot
+	for i := range h.counts {
+		c := h.counts[i].Load()
+		if c == 0 {
+			continue
+		}
+
+		s.Count += c
+		s.Buckets = append(s.Buckets, HistogramBucket{
+			UpperBound: histogramUpperBound(i),
+			Count:      c,
+		})
+	}
+	s.Sum = time.Duration(h.sum.Load()) // nolint:gosec
+
+	return s
+}
+
+func histogramIndex(v uint64) int {
+	if v < histogramSub {
+		return int(v)
+	}
+
+	exp := bits.Len64(v) - 1
+	if exp > histogramMaxExp {
+		return histogramBuckets - 1
+	}
+
+	sub := int(v>>(exp-histogramSubBits)) & (histogramSub - 1)
+	return (exp-histogramSubBits+1)*histogramSub + sub
+}
+
+// histogramUpperBound returns the exclusive upper bound of the bucket at the
+// given index.
+func histogramUpperBound(i int) time.Duration {
+	if i < histogramSub {
+		return time.Duration(i + 1)
+	}
+
+	if i == histogramBuckets-1 {
+		return time.Duration(math.MaxInt64)
+	}
+
+	exp := i/histogramSub + histogramSubBits - 1
+	sub := i % histogramSub
+	return time.Duration(histogramSub+sub+1) << (exp - histogramSubBits)
+}
+
+// HistogramSnapshot is a point in time copy of a latency histogram.
+type HistogramSnapshot struct {
+	// Count is the number of recorded durations.
+	Count uint64
+
+	// Sum is the sum of all recorded durations.
+	Sum time.Duration
+
+	// Buckets holds the non-empty buckets ordered by UpperBound.
+	Buckets []HistogramBucket
+}
+
+// HistogramBucket counts the durations that fell below UpperBound and at or
+// above the UpperBound of the previous bucket.
+type HistogramBucket struct {
+	UpperBound time.Duration
+	Count      uint64
+}
+
+// Mean returns the average recorded duration.
+func (s HistogramSnapshot) Mean() time.Duration {
+	if s.Count == 0 {
+		return 0
+	}
+
+	return s.Sum / time.Duration(s.Count) // nolint:gosec
+}
+
+// Quantile returns the upper bound of the bucket that holds the q-th
+// quantile (0 <= q <= 1) of the recorded durations.
+func (s HistogramSnapshot) Quantile(q float64) time.Duration {
+	if s.Count == 0 {
+		return 0
+	}
+
+	rank := uint64(math.Ceil(q * float64(s.Count)))
+	var seen uint64
+	for _, b := range s.Buckets {
+		seen += b.Count
+		if seen >= rank {
+			return b.UpperBound
+		}
+	}
+
+	return s.Buckets[len(s.Buckets)-1].UpperBound
+}
+
+// merge returns the sum of both snapshots.
+func (s HistogramSnapshot) merge(o HistogramSnapshot) HistogramSnapshot {
+	m := HistogramSnapshot{
+		Count: s.Count + o.Count,
+		Sum:   s.Sum + o.Sum,
+	}
+
+	i, j := 0, 0
+	for i < len(s.Buckets) || j < len(o.Buckets) {
+		switch {
+		case j == len(o.Buckets) || (i < len(s.Buckets) && s.Buckets[i].UpperBound < o.Buckets[j].UpperBound):
+			m.Buckets = append(m.Buckets, s.Buckets[i])
+			i++
+		case i == len(s.Buckets) || o.Buckets[j].UpperBound < s.Buckets[i].UpperBound:
+			m.Buckets = append(m.Buckets, o.Buckets[j])
+			j++
+		default:
+			m.Buckets = append(m.Buckets, HistogramBucket{
+				UpperBound: s.Buckets[i].UpperBound,
+				Count:      s.Buckets[i].Count + o.Buckets[j].Count,
+			})
+			i++
+			j++
+		}
+	}
+
+	return m
+}
@@ -0,0 +1,78 @@
+package diodes_test
+
+import (
+	"math"
+	"time"
+
+	"code.cloudfoundry.org/go-diodes"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("HistogramSnapshot", func() {
+	var (
+		d     *diodes.OneToOne
+		clock *fakeClock
+	)
+
+	BeforeEach(func() {
+		clock = newFakeClock()
+		d = diodes.NewOneToOne(1024, nil, diodes.WithClock(clock), diodes.WithLatencyHistogram())
+	})
+
+	record := func(latencies ...time.Duration) diodes.HistogramSnapshot {
+		data := 1
+		for _, l := range latencies {
+			d.Set(diodes.GenericDataType(&data))
+			clock.Advance(l)
+			d.TryNext()
+		}
+
+		return d.Stats().Latency
+	}
+
+	It("is empty without recorded values", func() {
+		s := record()
+		Expect(s.Count).To(BeZero())
+		Expect(s.Buckets).To(BeEmpty())
+		Expect(s.Mean()).To(BeZero())
+		Expect(s.Quantile(0.5)).To(BeZero())
+	})
+
+	It("gives small durations a bucket each", func() {
+		s := record(0, 3, 3)
+		Expect(s.Buckets).To(Equal([]diodes.HistogramBucket{
+			{UpperBound: 1, Count: 1},
+			{UpperBound: 4, Count: 2},
+		}))
+	})
+
+	It("keeps the relative error within an eighth", func() {
+		for _, l := range []time.Duration{9, 100, time.Microsecond, 17 * time.Millisecond, 3 * time.Second} {
+			s := record(l)
+			Expect(s.Quantile(1)).To(BeNumerically(">", l))
+			Expect(s.Quantile(1)).To(BeNumerically("<=", l+l/8+1))
+
+			d = diodes.NewOneToOne(1024, nil, diodes.WithClock(clock), diodes.WithLatencyHistogram())
+		}
+	})
+
+	It("counts very large durations in the last bucket", func() {
+		s := record(1000 * time.Hour)
+		Expect(s.Buckets).To(HaveLen(1))
+		Expect(s.Buckets[0].UpperBound).To(Equal(time.Duration(math.MaxInt64)))
+	})
+
+	It("orders buckets and computes quantiles", func() {
+		s := record(time.Millisecond, time.Second, time.Microsecond, time.Millisecond)
+		Expect(s.Count).To(Equal(uint64(4)))
+		Expect(s.Buckets).To(HaveLen(3))
+		Expect(s.Buckets[0].UpperBound).To(BeNumerically("<", s.Buckets[1].UpperBound))
+		Expect(s.Buckets[1].UpperBound).To(BeNumerically("<", s.Buckets[2].UpperBound))
+
+		Expect(s.Quantile(0.25)).To(BeNumerically("~", time.Microsecond, time.Microsecond/8))
+		Expect(s.Quantile(0.5)).To(BeNumerically("~", time.Millisecond, time.Millisecond/8))
+		Expect(s.Quantile(1)).To(BeNumerically("~", time.Second, time.Second/8))
+	})
+})
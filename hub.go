@@ -0,0 +1,95 @@
+package diodes
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+)
+
+// Hub fans out published data to any number of subscribers. Each subscriber
+// reads from its own ManyToOne diode, so a slow subscriber only drops its own
+// data and never slows down publishers or other subscribers. Publish,
+// Subscribe and Unsubscribe are safe to invoke concurrently.
+type Hub struct {
+	mu   sync.Mutex
+	subs atomic.Pointer[[]*Subscription]
+}
+
+// Subscription is a subscriber of a Hub. It is a Waiter over the subscriber's
+// own diode: Next blocks until data is published or the subscription is
+// removed from the Hub, in which case it returns nil. A Subscription has a
+// single reader.
+type Subscription struct {
+	*Waiter
+	hub    *Hub
+	cancel context.CancelFunc
+}
+
+// NewHub returns a Hub without any subscribers.
+func NewHub() *Hub {
+	h := new(Hub)
+	h.subs.Store(new([]*Subscription))
+
+	return h
+}
+
+// Publish sets the data in the diode of every current subscriber. It never
+// blocks on a subscriber. Data published without subscribers is discarded.
+func (h *Hub) Publish(data GenericDataType) {
+	for _, s := range *h.subs.Load() {
+		s.Set(data)
+	}
+}
+
+// Subscribe adds a subscriber with a diode of the given size. The alerter is
+// invoked on the subscriber's read go-routine when it notices that data was
+// dropped. A nil can be used to ignore alerts. The options are passed to the
+// subscriber's ManyToOne diode.
+func (h *Hub) Subscribe(size int, alerter Alerter, opts ...DiodeConfigOption) *Subscription {
+	ctx, cancel := context.WithCancel(context.Background())
+	s := &Subscription{
+		Waiter: NewWaiter(NewManyToOne(size, alerter, opts...), WithWaiterContext(ctx)),
+		hub:    h,
+		cancel: cancel,
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	old := *h.subs.Load()
+	subs := make([]*Subscription, len(old), len(old)+1)
+	copy(subs, old)
+	subs = append(subs, s)
+	h.subs.Store(&subs)
+
+	return s
+}
+
+// Unsubscribe removes the subscriber from the Hub. Data that is published
+// afterwards is not set in its diode and a blocked Next returns nil. It is
+// a no-op if the subscriber was already removed.
+func (h *Hub) Unsubscribe(s *Subscription) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	old := *h.subs.Load()
+	subs := make([]*Subscription, 0, len(old))
+	for _, sub := range old {
+		if sub != s {
+			subs = append(subs, sub)
+		}
+	}
+	h.subs.Store(&subs)
+
+	s.cancel()
+}
+
+// Subscribers returns the number of current subscribers.
+func (h *Hub) Subscribers() int {
+	return len(*h.subs.Load())
+}
+
+// Unsubscribe removes the subscription from its Hub.
+func (s *Subscription) Unsubscribe() {
+	s.hub.Unsubscribe(s)
+}
@@ -0,0 +1,115 @@
+package diodes_test
+
+import (
+	"sync"
+
+	"code.cloudfoundry.org/go-diodes"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Hub", func() {
+	var h *diodes.Hub
+
+	BeforeEach(func() {
+		h = diodes.NewHub()
+	})
+
+	It("publishes to every subscriber", func() {
+		a := h.Subscribe(4, nil)
+		b := h.Subscribe(4, nil)
+		Expect(h.Subscribers()).To(Equal(2))
+
+		data := 7
+		h.Publish(diodes.GenericDataType(&data))
+
+		Expect(*(*int)(a.Next())).To(Equal(7))
+		Expect(*(*int)(b.Next())).To(Equal(7))
+	})
+
+	It("does not publish to subscribers that were added later", func() {
+		data := 1
+		h.Publish(diodes.GenericDataType(&data))
+		s := h.Subscribe(4, nil)
+
+		_, ok := s.TryNext()
+		Expect(ok).To(BeFalse())
+	})
+
+	It("drops data only for the slow subscriber", func() {
+		slowSpy := newSpyAlerter()
+		fastSpy := newSpyAlerter()
+		slow := h.Subscribe(2, slowSpy)
+		fast := h.Subscribe(2, fastSpy)
+
+		for i := 0; i < 5; i++ {
+			data := i
+			h.Publish(diodes.GenericDataType(&data))
+			Expect(*(*int)(fast.Next())).To(Equal(i))
+		}
+
+		Expect(*(*int)(slow.Next())).To(Equal(4))
+		Expect(slowSpy.AlertInput.Missed).To(Receive(Equal(4)))
+		Expect(fastSpy.AlertCalled).ToNot(Receive())
+	})
+
+	Describe("Unsubscribe", func() {
+		It("stops publishing to the subscriber", func() {
+			a := h.Subscribe(4, nil)
+			b := h.Subscribe(4, nil)
+			h.Unsubscribe(a)
+			Expect(h.Subscribers()).To(Equal(1))
+
+			data := 1
+			h.Publish(diodes.GenericDataType(&data))
+
+			_, ok := a.TryNext()
+			Expect(ok).To(BeFalse())
+			_, ok = b.TryNext()
+			Expect(ok).To(BeTrue())
+		})
+
+		It("unblocks a waiting reader", func() {
+			s := h.Subscribe(4, nil)
+			next := make(chan bool)
+			go func() {
+				next <- s.Next() == nil
+			}()
+
+			s.Unsubscribe()
+			Eventually(next).Should(Receive(BeTrue()))
+		})
+
+		It("can be invoked more than once", func() {
+			s := h.Subscribe(4, nil)
+			s.Unsubscribe()
+			s.Unsubscribe()
+
+			Expect(h.Subscribers()).To(Equal(0))
+		})
+	})
+
+	It("is safe to publish while subscribing", func() {
+		var wg sync.WaitGroup
+		for i := 0; i < 4; i++ {
+			wg.Add(2)
+			go func() {
+				defer wg.Done()
+				data := 1
+				for j := 0; j < 1000; j++ {
+					h.Publish(diodes.GenericDataType(&data))
+				}
+			}()
+			go func() {
+				defer wg.Done()
+				for j := 0; j < 50; j++ {
+					h.Subscribe(16, nil).Unsubscribe()
+				}
+			}()
+		}
+		wg.Wait()
+
+		Expect(h.Subscribers()).To(Equal(0))
+	})
+})
@@ -0,0 +1,121 @@
+package diodes
+
+import (
+	"sync"
+	"sync/atomic"
+	"unsafe"
+)
+
+// keyedSlot holds the latest unread value for a single key. A slot is linked
+// into the dirty list at most once: when its value goes from nil to non-nil.
+// A nil value is stored as keyedNil so that it still marks the slot dirty.
+type keyedSlot struct {
+	key  string
+	data unsafe.Pointer
+	next *keyedSlot
+}
+
+// keyedNil stands in for a nil value in a keyedSlot.
+var keyedNil = unsafe.Pointer(new(byte))
+
+// KeyedDiode is a conflating diode that only keeps the latest value for each
+// key. It is optimal for many writers (go-routines B-n) and a single reader
+// (go-routine A). It is not thread safe for multiple readers.
+type KeyedDiode struct {
+	conflated uint64
+	dirty     unsafe.Pointer
+	slots     sync.Map
+	pending   *keyedSlot
+	alerter   Alerter
+}
+
+// NewKeyedDiode creates a new conflating diode. A value that is Set for a key
+// replaces any value for the same key that has not been read yet. The alerter
+// is invoked on the read's go-routine with the number of values that were
+// replaced (conflated) since the last alert. A nil can be used to ignore
+// alerts.
+func NewKeyedDiode(alerter Alerter) *KeyedDiode {
+	if alerter == nil {
+		alerter = AlertFunc(func(int) {})
+	}
+
+	return &KeyedDiode{
+		alerter: alerter,
+	}
+}
+
+// Set sets the data for the given key, replacing any unread data for the
+// same key.
+func (d *KeyedDiode) Set(key string, data GenericDataType) {
+	s, ok := d.slots.Load(key)
+	if !ok {
+		s, _ = d.slots.LoadOrStore(key, &keyedSlot{key: key})
+	}
+	slot := s.(*keyedSlot)
+
+	value := unsafe.Pointer(data)
+	if value == nil {
+		value = keyedNil
+	}
+
+	// When the old value is not nil the reader has not consumed it yet. The
+	// slot is already in the dirty list so the value is replaced in place
+	// and counted as conflated.
+	if atomic.SwapPointer(&slot.data, value) != nil {
+		atomic.AddUint64(&d.conflated, 1)
+		return
+	}
+
+	// The slot went from clean to dirty. It is pushed onto the dirty list so
+	// the reader can find it.
+	for {
+		head := atomic.LoadPointer(&d.dirty)
+		slot.next = (*keyedSlot)(head)
+		if atomic.CompareAndSwapPointer(&d.dirty, head, unsafe.Pointer(slot)) {
+			return
+		}
+	}
+}
+
+// TryNext will attempt to read the latest value of the next dirty key. Keys
+// are returned in the order in which they became dirty. If there is no data
+// available, it will return ("", nil, false).
+func (d *KeyedDiode) TryNext() (key string, data GenericDataType, ok bool) {
+	if d.pending == nil {
+		d.takeDirty()
+	}
+
+	slot := d.pending
+	if slot == nil {
+		return "", nil, false
+	}
+	d.pending = slot.next
+
+	// The value is swapped out after the slot has been unlinked. Any write
+	// after this point will see a nil value and push the slot again.
+	value := atomic.SwapPointer(&slot.data, nil)
+	if value != keyedNil {
+		data = GenericDataType(value)
+	}
+
+	if conflated := atomic.SwapUint64(&d.conflated, 0); conflated > 0 {
+		d.alerter.Alert(int(conflated)) // nolint:gosec
+	}
+
+	return slot.key, data, true
+}
+
+// takeDirty takes every slot the writers have pushed so far and reverses
+// them so the oldest dirty key is read first.
+func (d *KeyedDiode) takeDirty() {
+	slot := (*keyedSlot)(atomic.SwapPointer(&d.dirty, nil))
+
+	var pending *keyedSlot
+	for slot != nil {
+		next := slot.next
+		slot.next = pending
+		pending = slot
+		slot = next
+	}
+	d.pending = pending
+}
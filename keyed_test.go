@@ -0,0 +1,176 @@
+package diodes_test
+
+import (
+	"fmt"
+	"sync"
+
+	"code.cloudfoundry.org/go-diodes"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("KeyedDiode", func() {
+	var (
+		d   *diodes.KeyedDiode
+		spy *spyAlerter
+	)
+
+	BeforeEach(func() {
+		spy = newSpyAlerter()
+		d = diodes.NewKeyedDiode(spy)
+	})
+
+	Describe("TryNext()", func() {
+		It("returns false when there is no data", func() {
+			_, _, ok := d.TryNext()
+			Expect(ok).To(BeFalse())
+		})
+
+		It("returns each dirty key once in the order they were set", func() {
+			a, b := 1, 2
+			d.Set("a", diodes.GenericDataType(&a))
+			d.Set("b", diodes.GenericDataType(&b))
+
+			key, data, ok := d.TryNext()
+			Expect(ok).To(BeTrue())
+			Expect(key).To(Equal("a"))
+			Expect(*(*int)(data)).To(Equal(1))
+
+			key, data, ok = d.TryNext()
+			Expect(ok).To(BeTrue())
+			Expect(key).To(Equal("b"))
+			Expect(*(*int)(data)).To(Equal(2))
+
+			_, _, ok = d.TryNext()
+			Expect(ok).To(BeFalse())
+		})
+
+		It("returns the latest value for a key", func() {
+			for i := 0; i < 3; i++ {
+				j := i
+				d.Set("a", diodes.GenericDataType(&j))
+			}
+
+			key, data, ok := d.TryNext()
+			Expect(ok).To(BeTrue())
+			Expect(key).To(Equal("a"))
+			Expect(*(*int)(data)).To(Equal(2))
+
+			_, _, ok = d.TryNext()
+			Expect(ok).To(BeFalse())
+		})
+
+		It("returns a key again once it is set after being read", func() {
+			a, b := 1, 2
+			d.Set("a", diodes.GenericDataType(&a))
+			d.TryNext()
+			d.Set("a", diodes.GenericDataType(&b))
+
+			_, data, ok := d.TryNext()
+			Expect(ok).To(BeTrue())
+			Expect(*(*int)(data)).To(Equal(2))
+		})
+
+		It("treats nil as a value", func() {
+			a := 1
+			d.Set("a", nil)
+			d.Set("a", diodes.GenericDataType(&a))
+			d.Set("b", nil)
+
+			key, data, ok := d.TryNext()
+			Expect(ok).To(BeTrue())
+			Expect(key).To(Equal("a"))
+			Expect(*(*int)(data)).To(Equal(1))
+
+			key, data, ok = d.TryNext()
+			Expect(ok).To(BeTrue())
+			Expect(key).To(Equal("b"))
+			Expect(data == nil).To(BeTrue())
+
+			_, _, ok = d.TryNext()
+			Expect(ok).To(BeFalse())
+			Expect(spy.AlertInput.Missed).To(Receive(Equal(1)))
+		})
+	})
+
+	Describe("alerts", func() {
+		It("reports the number of conflated writes", func() {
+			for i := 0; i < 4; i++ {
+				j := i
+				d.Set("a", diodes.GenericDataType(&j))
+			}
+			d.TryNext()
+
+			Expect(spy.AlertInput.Missed).To(Receive(Equal(3)))
+		})
+
+		It("does not alert when nothing was conflated", func() {
+			a := 1
+			d.Set("a", diodes.GenericDataType(&a))
+			d.TryNext()
+
+			Expect(spy.AlertCalled).ToNot(Receive())
+		})
+
+		It("accepts a nil alerter", func() {
+			d = diodes.NewKeyedDiode(nil)
+			a := 1
+			d.Set("a", diodes.GenericDataType(&a))
+			d.Set("a", diodes.GenericDataType(&a))
+
+			Expect(func() {
+				d.TryNext()
+			}).ToNot(Panic())
+		})
+	})
+
+	It("delivers the final value of every key with many writers", func() {
+		d = diodes.NewKeyedDiode(nil)
+
+		var wg sync.WaitGroup
+		for w := 0; w < 4; w++ {
+			wg.Add(1)
+			go func(w int) {
+				defer wg.Done()
+				for i := 0; i <= 1000; i++ {
+					j := i
+					d.Set(fmt.Sprintf("key-%d", w), diodes.GenericDataType(&j))
+				}
+			}(w)
+		}
+
+		latest := make(map[string]int)
+		done := make(chan struct{})
+		go func() {
+			wg.Wait()
+			close(done)
+		}()
+
+		drain := func() {
+			for {
+				key, data, ok := d.TryNext()
+				if !ok {
+					return
+				}
+				latest[key] = *(*int)(data)
+			}
+		}
+
+		Eventually(func() bool {
+			drain()
+			select {
+			case <-done:
+				drain()
+				return true
+			default:
+				return false
+			}
+		}).Should(BeTrue())
+
+		Expect(latest).To(HaveLen(4))
+		for _, v := range latest {
+			Expect(v).To(Equal(1000))
+		}
+	})
+})
@@ -0,0 +1,63 @@
+package diodes
+
+import (
+	"sync/atomic"
+	"unsafe"
+)
+
+// Latest diode holds a single value. It is meant for values where the reader
+// only ever wants the newest one (e.g. configuration or status). It is safe
+// for many writers and a single reader.
+type Latest struct {
+	overwritten uint64
+	data        unsafe.Pointer
+	alerter     Alerter
+}
+
+// latestNil stands in for a nil value, since a nil data means that there is
+// no new value.
+var latestNil = unsafe.Pointer(new(byte))
+
+// NewLatest creates a new single slot diode. The alerter is invoked on the
+// read's go-routine. It is called with the number of values that were
+// overwritten since the last read. A nil can be used to ignore alerts.
+func NewLatest(alerter Alerter) *Latest {
+	if alerter == nil {
+		alerter = AlertFunc(func(int) {})
+	}
+
+	return &Latest{
+		alerter: alerter,
+	}
+}
+
+// Set replaces the value held by the diode.
+func (d *Latest) Set(data GenericDataType) {
+	value := unsafe.Pointer(data)
+	if value == nil {
+		value = latestNil
+	}
+
+	if atomic.SwapPointer(&d.data, value) != nil {
+		atomic.AddUint64(&d.overwritten, 1)
+	}
+}
+
+// TryNext will attempt to read the value held by the diode. Each value is
+// only returned once. If there is no new value, it will return (nil, false).
+func (d *Latest) TryNext() (data GenericDataType, ok bool) {
+	result := atomic.SwapPointer(&d.data, nil)
+	if result == nil {
+		return nil, false
+	}
+
+	if overwritten := atomic.SwapUint64(&d.overwritten, 0); overwritten > 0 {
+		d.alerter.Alert(int(overwritten)) // nolint:gosec
+	}
+
+	if result == latestNil {
+		return nil, true
+	}
+
+	return GenericDataType(result), true
+}
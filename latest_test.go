@@ -0,0 +1,105 @@
+package diodes_test
+
+import (
+	"context"
+	"time"
+
+	"code.cloudfoundry.org/go-diodes"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Latest", func() {
+	var (
+		d   *diodes.Latest
+		spy *spyAlerter
+	)
+
+	BeforeEach(func() {
+		spy = newSpyAlerter()
+		d = diodes.NewLatest(spy)
+	})
+
+	Describe("TryNext()", func() {
+		It("returns false when nothing was set", func() {
+			_, ok := d.TryNext()
+			Expect(ok).To(BeFalse())
+		})
+
+		It("returns the newest value once", func() {
+			for i := 0; i < 3; i++ {
+				j := i
+				d.Set(diodes.GenericDataType(&j))
+			}
+
+			data, ok := d.TryNext()
+			Expect(ok).To(BeTrue())
+			Expect(*(*int)(data)).To(Equal(2))
+
+			_, ok = d.TryNext()
+			Expect(ok).To(BeFalse())
+		})
+
+		It("treats nil as a value", func() {
+			data := 1
+			d.Set(diodes.GenericDataType(&data))
+			d.Set(nil)
+
+			result, ok := d.TryNext()
+			Expect(ok).To(BeTrue())
+			Expect(result == nil).To(BeTrue())
+			Expect(spy.AlertInput.Missed).To(Receive(Equal(1)))
+
+			_, ok = d.TryNext()
+			Expect(ok).To(BeFalse())
+		})
+	})
+
+	Describe("alerts", func() {
+		It("reports how many values were overwritten between reads", func() {
+			for i := 0; i < 3; i++ {
+				j := i
+				d.Set(diodes.GenericDataType(&j))
+			}
+			d.TryNext()
+
+			Expect(spy.AlertInput.Missed).To(Receive(Equal(2)))
+		})
+
+		It("does not alert when nothing was overwritten", func() {
+			data := 1
+			d.Set(diodes.GenericDataType(&data))
+			d.TryNext()
+			d.Set(diodes.GenericDataType(&data))
+			d.TryNext()
+
+			Expect(spy.AlertCalled).ToNot(Receive())
+		})
+
+		It("accepts a nil alerter", func() {
+			d = diodes.NewLatest(nil)
+			data := 1
+			d.Set(diodes.GenericDataType(&data))
+			d.Set(diodes.GenericDataType(&data))
+
+			Expect(func() {
+				d.TryNext()
+			}).ToNot(Panic())
+		})
+	})
+
+	It("can be used with a Waiter for change notification", func() {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		w := diodes.NewWaiter(d, diodes.WithWaiterContext(ctx))
+
+		go func() {
+			time.Sleep(50 * time.Millisecond)
+			data := 42
+			w.Set(diodes.GenericDataType(&data))
+		}()
+
+		Expect(*(*int)(w.Next())).To(Equal(42))
+	})
+})
@@ -0,0 +1,150 @@
+package diodes_test
+
+import (
+	"sync"
+
+	"code.cloudfoundry.org/go-diodes"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+type resetDiode interface {
+	diodes.Diode
+	diodes.StatsReporter
+	Len() int
+	Cap() int
+	Reset()
+}
+
+var _ = Describe("Len, Cap and Reset", func() {
+	for _, storage := range storageTypes {
+		constructor := storage.constructor
+
+		Context(storage.name, func() {
+			var (
+				reasons []diodes.DropReason
+				missed  []int
+				d       resetDiode
+			)
+
+			set := func(n int) {
+				data := 1
+				for i := 0; i < n; i++ {
+					d.Set(diodes.GenericDataType(&data))
+				}
+			}
+
+			BeforeEach(func() {
+				reasons = nil
+				missed = nil
+				d = constructor(4, diodes.ReasonAlertFunc(func(m int, reason diodes.DropReason) {
+					missed = append(missed, m)
+					reasons = append(reasons, reason)
+				})).(resetDiode)
+			})
+
+			It("returns the capacity", func() {
+				Expect(d.Cap()).To(Equal(4))
+			})
+
+			It("returns the number of unread values", func() {
+				Expect(d.Len()).To(BeZero())
+
+				set(3)
+				Expect(d.Len()).To(Equal(3))
+
+				d.TryNext()
+				Expect(d.Len()).To(Equal(2))
+			})
+
+			It("does not return more than the capacity", func() {
+				set(10)
+				Expect(d.Len()).To(Equal(4))
+			})
+
+			It("discards unread values on Reset", func() {
+				set(3)
+				d.TryNext()
+				d.Reset()
+
+				Expect(d.Len()).To(BeZero())
+				_, ok := d.TryNext()
+				Expect(ok).To(BeFalse())
+				Expect(missed).To(Equal([]int{2}))
+				Expect(reasons).To(Equal([]diodes.DropReason{diodes.DropReset}))
+				Expect(d.Stats().Dropped).To(Equal(uint64(2)))
+				Expect(d.Stats().Reset).To(Equal(uint64(2)))
+			})
+
+			It("reports the values that were overwritten before the Reset", func() {
+				set(10)
+				d.Reset()
+
+				Expect(missed).To(Equal([]int{10}))
+			})
+
+			It("does not alert when there is nothing to discard", func() {
+				d.Reset()
+				set(1)
+				d.TryNext()
+				d.Reset()
+
+				Expect(missed).To(BeEmpty())
+			})
+
+			It("keeps values written after the Reset", func() {
+				set(6)
+				d.Reset()
+
+				data := 7
+				d.Set(diodes.GenericDataType(&data))
+				result, ok := d.TryNext()
+				Expect(ok).To(BeTrue())
+				Expect(*(*int)(result)).To(Equal(7))
+				Expect(reasons).To(Equal([]diodes.DropReason{diodes.DropReset}))
+			})
+		})
+	}
+
+	It("is safe to reset while writers keep writing", func() {
+		var dropped, reads int
+		d := diodes.NewManyToOne(16, diodes.AlertFunc(func(missed int) {
+			dropped += missed
+		}))
+
+		var wg sync.WaitGroup
+		for i := 0; i < 4; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				data := 1
+				for j := 0; j < 1000; j++ {
+					d.Set(diodes.GenericDataType(&data))
+				}
+			}()
+		}
+
+		done := make(chan struct{})
+		go func() {
+			wg.Wait()
+			close(done)
+		}()
+
+	loop:
+		for {
+			select {
+			case <-done:
+				break loop
+			default:
+				if _, ok := d.TryNext(); ok {
+					reads++
+				}
+				d.Reset()
+			}
+		}
+		d.Reset()
+
+		Expect(reads + dropped).To(Equal(4000))
+	})
+})
@@ -10,25 +10,19 @@ import (
 // reader (go-routine A). It is not thread safe for multiple readers.
 type ManyToOne struct {
 	writeIndex uint64
-	buffer     []unsafe.Pointer
-	readIndex  uint64
-	alerter    Alerter
+	collisions uint64
+	ring
 }
 
 // NewManyToOne creates a new diode (ring buffer). The ManyToOne diode
 // is optimzed for many writers (on go-routines B-n) and a single reader
 // (on go-routine A). The alerter is invoked on the read's go-routine. It is
 // called when it notices that the writer go-routine has passed it and wrote
-// over data. A nil can be used to ignore alerts.
-func NewManyToOne(size int, alerter Alerter) *ManyToOne {
-	if alerter == nil {
-		alerter = AlertFunc(func(int) {})
-	}
-
-	d := &ManyToOne{
-		buffer:  make([]unsafe.Pointer, size),
-		alerter: alerter,
-	}
+// over data. A nil can be used to ignore alerts. The size is rounded up to
+// the next power of two.
+func NewManyToOne(size int, alerter Alerter, opts ...DiodeConfigOption) *ManyToOne {
+	d := new(ManyToOne)
+	d.init(size, alerter, opts)
 
 	// Start write index at the value before 0
 	// to allow the first write to use AddUint64
@@ -39,14 +33,29 @@ func NewManyToOne(size int, alerter Alerter) *ManyToOne {
 
 // Set sets the data in the next slot of the ring buffer.
 func (d *ManyToOne) Set(data GenericDataType) {
+	d.set(data, 0, nil, 0)
+}
+
+// SetTagged sets the data with the given tag in the next slot of the ring
+// buffer.
+func (d *ManyToOne) SetTagged(tag Tag, data GenericDataType) {
+	d.set(data, tag, nil, 0)
+}
+
+// set sets the data in the next slot of the ring buffer on behalf of the
+// given producer, if any.
+func (d *ManyToOne) set(data GenericDataType, tag Tag, p *Producer, pseq uint64) {
+	meta := d.newMeta(tag, p, pseq)
+
 	for {
 		writeIndex := atomic.AddUint64(&d.writeIndex, 1)
-		idx := writeIndex % uint64(len(d.buffer))
-		old := atomic.LoadPointer(&d.buffer[idx])
+		slot := d.slot(writeIndex)
+		old := atomic.LoadPointer(slot)
 
 		if old != nil &&
 			(*bucket)(old) != nil &&
-			(*bucket)(old).seq > writeIndex-uint64(len(d.buffer)) {
+			seqDiff((*bucket)(old).seq, writeIndex-uint64(len(d.buffer))) > 0 {
+			atomic.AddUint64(&d.collisions, 1)
 			log.Println("Diode set collision: consider using a larger diode")
 			continue
 		}
@@ -54,13 +63,16 @@ func (d *ManyToOne) Set(data GenericDataType) {
 		newBucket := &bucket{
 			data: data,
 			seq:  writeIndex,
+			meta: meta,
 		}
 
-		if !atomic.CompareAndSwapPointer(&d.buffer[idx], old, unsafe.Pointer(newBucket)) {
+		if !atomic.CompareAndSwapPointer(slot, old, unsafe.Pointer(newBucket)) {
+			atomic.AddUint64(&d.collisions, 1)
 			log.Println("Diode set collision: consider using a larger diode")
 			continue
 		}
 
+		d.overwrote(old)
 		return
 	}
 }
@@ -68,63 +80,75 @@ func (d *ManyToOne) Set(data GenericDataType) {
 // TryNext will attempt to read from the next slot of the ring buffer.
 // If there is not data available, it will return (nil, false).
 func (d *ManyToOne) TryNext() (data GenericDataType, ok bool) {
-	// Read a value from the ring buffer based on the readIndex.
-	idx := d.readIndex % uint64(len(d.buffer))
-	result := (*bucket)(atomic.SwapPointer(&d.buffer[idx], nil))
-
-	// When the result is nil that means the writer has not had the
-	// opportunity to write a value into the diode. This value must be ignored
-	// and the read head must not increment.
-	if result == nil {
-		return nil, false
-	}
+	data, _, ok = d.TryNextSeq()
+	return data, ok
+}
 
-	// When the seq value is less than the current read index that means a
-	// value was read from idx that was previously written but has since has
-	// been dropped. This value must be ignored and the read head must not
-	// increment.
-	//
-	// The simulation for this scenario assumes the fast forward occurred as
-	// detailed below.
-	//
-	// 5. The reader reads again getting seq 5. It then reads again expecting
-	//    seq 6 but gets seq 2. This is a read of a stale value that was
-	//    effectively "dropped" so the read fails and the read head stays put.
-	//    `| 4 | 5 | 2 | 3 |` r: 7, w: 6
-	//
-	if result.seq < d.readIndex {
-		return nil, false
+// TryNextSeq is like TryNext, but also returns the sequence number the data
+// was written with. Sequence numbers start at 0 and grow by one with each
+// Set, so a gap between two reads is the number of values that were lost.
+func (d *ManyToOne) TryNextSeq() (data GenericDataType, seq uint64, ok bool) {
+	for {
+		result, ok := d.next()
+		if !ok {
+			return nil, 0, false
+		}
+
+		if p := result.producer(); p != nil && !p.deliver(result.pseq()) {
+			d.lostProducer(p)
+			continue
+		}
+
+		return result.data, result.seq, true
 	}
+}
+
+// Len returns the approximate number of values that were not read yet. It is
+// at most Cap. It is safe to call from any go-routine.
+func (d *ManyToOne) Len() int {
+	return d.len(atomic.LoadUint64(&d.writeIndex) + 1)
+}
+
+// Cap returns the size of the ring buffer, which is a power of two.
+func (d *ManyToOne) Cap() int {
+	return len(d.buffer)
+}
 
-	// When the seq value is greater than the current read index that means a
-	// value was read from idx that overwrote the value that was expected to
-	// be at this idx. This happens when the writer has lapped the reader. The
-	// reader needs to catch up to the writer so it moves its write head to
-	// the new seq, effectively dropping the messages that were not read in
-	// between the two values.
-	//
-	// Here is a simulation of this scenario:
-	//
-	// 1. Both the read and write heads start at 0.
-	//    `| nil | nil | nil | nil |` r: 0, w: 0
-	// 2. The writer fills the buffer.
-	//    `| 0 | 1 | 2 | 3 |` r: 0, w: 4
-	// 3. The writer laps the read head.
-	//    `| 4 | 5 | 2 | 3 |` r: 0, w: 6
-	// 4. The reader reads the first value, expecting a seq of 0 but reads 4,
-	//    this forces the reader to fast forward to 5.
-	//    `| 4 | 5 | 2 | 3 |` r: 5, w: 6
-	//
-	if result.seq > d.readIndex {
-		dropped := result.seq - d.readIndex
-		d.readIndex = result.seq
-		d.alerter.Alert(int(dropped)) // nolint:gosec
+// Reset discards all values that were not read yet and reports them to the
+// alerter as dropped with the DropReset reason. It is safe to call while
+// writers keep writing, but it must be called by the reader: the alerter is
+// invoked on the calling go-routine.
+func (d *ManyToOne) Reset() {
+	d.reset(atomic.LoadUint64(&d.writeIndex) + 1)
+}
+
+// Peek returns the data the next TryNext would return without consuming it.
+// If there is no data available, it will return (nil, false). It is meant to
+// be used by the reader. From other go-routines the result is best effort.
+func (d *ManyToOne) Peek() (data GenericDataType, ok bool) {
+	result, ok := d.peek()
+	if !ok {
+		return nil, false
 	}
 
-	// Only increment read index if a regular read occurred (where seq was
-	// equal to readIndex) or a value was read that caused a fast forward
-	// (where seq was greater than readIndex).
-	//
-	d.readIndex++
 	return result.data, true
 }
+
+// Snapshot returns a best effort copy of the unread data ordered by sequence
+// number, without consuming it. It is safe to call from any go-routine. The
+// data is shared with the reader and must not be modified.
+func (d *ManyToOne) Snapshot() []SnapshotEntry {
+	return d.snapshot(atomic.LoadUint64(&d.writeIndex) + 1)
+}
+
+// Stats returns a snapshot of the diode's counters. It is safe to call from
+// any go-routine.
+func (d *ManyToOne) Stats() Stats {
+	// Every collision skips a write index. The collisions are loaded first
+	// so that each of them is already included in the write index.
+	collisions := atomic.LoadUint64(&d.collisions)
+	s := d.stats(atomic.LoadUint64(&d.writeIndex) + 1)
+	s.Writes -= collisions
+	s.Collisions = collisions
+	return s
+}
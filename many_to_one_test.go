@@ -18,7 +18,7 @@ var _ = Describe("ManyToOne", func() {
 	BeforeEach(func() {
 		spy = newSpyAlerter()
 
-		d = diodes.NewManyToOne(5, spy)
+		d = diodes.NewManyToOne(4, spy)
 
 		data = []byte("some-data")
 		d.Set(diodes.GenericDataType(&data))
@@ -55,9 +55,25 @@ var _ = Describe("ManyToOne", func() {
 			})
 		})
 
+		Describe("TryNextSeq()", func() {
+			It("returns the sequence numbers", func() {
+				result, seq, ok := d.TryNextSeq()
+				Expect(ok).To(BeTrue())
+				Expect(*(*[]byte)(result)).To(Equal(data))
+				Expect(seq).To(BeZero())
+
+				_, seq, ok = d.TryNextSeq()
+				Expect(ok).To(BeTrue())
+				Expect(seq).To(Equal(uint64(1)))
+
+				_, _, ok = d.TryNextSeq()
+				Expect(ok).To(BeFalse())
+			})
+		})
+
 		Context("buffer size exceeded", func() {
 			BeforeEach(func() {
-				for i := 0; i < 4; i++ {
+				for i := 0; i < 3; i++ {
 					d.Set(diodes.GenericDataType(&secondData))
 				}
 			})
@@ -67,14 +83,19 @@ var _ = Describe("ManyToOne", func() {
 				Expect(*(*[]byte)(data)).To(Equal(secondData))
 			})
 
+			It("skips the sequence numbers of dropped points", func() {
+				_, seq, _ := d.TryNextSeq()
+				Expect(seq).To(Equal(uint64(4)))
+			})
+
 			It("alerts for each dropped point", func() {
 				d.TryNext()
-				Expect(spy.AlertInput.Missed).To(Receive(Equal(5)))
+				Expect(spy.AlertInput.Missed).To(Receive(Equal(4)))
 			})
 
 			It("it updates the read index", func() {
 				d.TryNext()
-				Expect(spy.AlertInput.Missed).To(Receive(Equal(5)))
+				Expect(spy.AlertInput.Missed).To(Receive(Equal(4)))
 
 				for i := 0; i < 6; i++ {
 					j := i
@@ -82,8 +103,8 @@ var _ = Describe("ManyToOne", func() {
 				}
 
 				data, _ := d.TryNext()
-				Expect(*(*int)(data)).To(Equal(5))
-				Expect(spy.AlertInput.Missed).To(Receive(Equal(5)))
+				Expect(*(*int)(data)).To(Equal(4))
+				Expect(spy.AlertInput.Missed).To(Receive(Equal(4)))
 			})
 
 			Context("read catches up with write", func() {
@@ -100,14 +121,14 @@ var _ = Describe("ManyToOne", func() {
 
 			Context("writer laps reader", func() {
 				BeforeEach(func() {
-					for i := 0; i < 5; i++ {
+					for i := 0; i < 4; i++ {
 						d.Set(diodes.GenericDataType(&secondData))
 					}
 					d.TryNext()
 				})
 
 				It("sends an alert for each set", func() {
-					Expect(spy.AlertInput.Missed).To(Receive(Equal(10)))
+					Expect(spy.AlertInput.Missed).To(Receive(Equal(8)))
 				})
 			})
 
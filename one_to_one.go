@@ -23,107 +23,199 @@ func (f AlertFunc) Alert(missed int) {
 	f(missed)
 }
 
+// DropReason describes why values were dropped.
+type DropReason int
+
+const (
+	// DropOverwritten means the writer lapped the reader and wrote over
+	// values that were not read yet.
+	DropOverwritten DropReason = iota
+
+	// DropExpired means the values were older than the max age set with
+	// WithMaxAge by the time the reader got to them.
+	DropExpired
+
+	// DropReset means the values were discarded by Reset.
+	DropReset
+)
+
+// String returns a short name for the reason.
+func (r DropReason) String() string {
+	switch r {
+	case DropOverwritten:
+		return "overwritten"
+	case DropExpired:
+		return "expired"
+	case DropReset:
+		return "reset"
+	default:
+		return "unknown"
+	}
+}
+
+// ReasonAlerter is an Alerter that is also told why values were dropped. When
+// the alerter given to a diode implements ReasonAlerter, AlertReason is
+// invoked instead of Alert.
+type ReasonAlerter interface {
+	Alerter
+	AlertReason(missed int, reason DropReason)
+}
+
+// ReasonAlertFunc type is an adapter to allow the use of ordinary functions
+// as ReasonAlerters.
+type ReasonAlertFunc func(missed int, reason DropReason)
+
+// Alert calls f(missed, DropOverwritten)
+func (f ReasonAlertFunc) Alert(missed int) {
+	f(missed, DropOverwritten)
+}
+
+// AlertReason calls f(missed, reason)
+func (f ReasonAlertFunc) AlertReason(missed int, reason DropReason) {
+	f(missed, reason)
+}
+
 type bucket struct {
 	data GenericDataType
-	seq  uint64 // seq is the recorded write index at the time of writing
+	seq  uint64      // seq is the recorded write index at the time of writing
+	meta *bucketMeta // meta is only set when the data needs it
+}
+
+// bucketMeta holds what is stored with the data when an option or a
+// Producer needs it. Keeping it out of bucket keeps plain writes small.
+type bucketMeta struct {
+	ts       int64     // ts is the write time in nanoseconds, when stamping is enabled
+	producer *Producer // producer is the ManyToOne Producer that wrote the data, if any
+	pseq     uint64    // pseq is the producer's sequence number of the data
+	tag      Tag       // tag is the tag the data was written with
+}
+
+func (b *bucket) ts() int64 {
+	if b.meta == nil {
+		return 0
+	}
+	return b.meta.ts
+}
+
+func (b *bucket) producer() *Producer {
+	if b.meta == nil {
+		return nil
+	}
+	return b.meta.producer
+}
+
+func (b *bucket) pseq() uint64 {
+	if b.meta == nil {
+		return 0
+	}
+	return b.meta.pseq
+}
+
+func (b *bucket) tag() Tag {
+	if b.meta == nil {
+		return 0
+	}
+	return b.meta.tag
 }
 
 // OneToOne diode is meant to be used by a single reader and a single writer.
 // It is not thread safe if used otherwise.
 type OneToOne struct {
-	buffer     []unsafe.Pointer
 	writeIndex uint64
-	readIndex  uint64
-	alerter    Alerter
+	ring
 }
 
 // NewOneToOne creates a new diode is meant to be used by a single reader and
 // a single writer. The alerter is invoked on the read's go-routine. It is
 // called when it notices that the writer go-routine has passed it and wrote
-// over data. A nil can be used to ignore alerts.
-func NewOneToOne(size int, alerter Alerter) *OneToOne {
-	if alerter == nil {
-		alerter = AlertFunc(func(int) {})
-	}
-
-	return &OneToOne{
-		buffer:  make([]unsafe.Pointer, size),
-		alerter: alerter,
-	}
+// over data. A nil can be used to ignore alerts. The size is rounded up to
+// the next power of two.
+func NewOneToOne(size int, alerter Alerter, opts ...DiodeConfigOption) *OneToOne {
+	d := new(OneToOne)
+	d.init(size, alerter, opts)
+	return d
 }
 
 // Set sets the data in the next slot of the ring buffer.
 func (d *OneToOne) Set(data GenericDataType) {
-	idx := d.writeIndex % uint64(len(d.buffer))
+	d.SetTagged(0, data)
+}
+
+// SetTagged sets the data with the given tag in the next slot of the ring
+// buffer.
+func (d *OneToOne) SetTagged(tag Tag, data GenericDataType) {
+	writeIndex := d.writeIndex
 
 	newBucket := &bucket{
 		data: data,
-		seq:  d.writeIndex,
+		seq:  writeIndex,
+		meta: d.newMeta(tag, nil, 0),
 	}
-	d.writeIndex++
+	atomic.StoreUint64(&d.writeIndex, writeIndex+1)
 
-	atomic.StorePointer(&d.buffer[idx], unsafe.Pointer(newBucket))
+	old := atomic.SwapPointer(d.slot(writeIndex), unsafe.Pointer(newBucket))
+	d.overwrote(old)
 }
 
 // TryNext will attempt to read from the next slot of the ring buffer.
 // If there is no data available, it will return (nil, false).
 func (d *OneToOne) TryNext() (data GenericDataType, ok bool) {
-	// Read a value from the ring buffer based on the readIndex.
-	idx := d.readIndex % uint64(len(d.buffer))
-	result := (*bucket)(atomic.SwapPointer(&d.buffer[idx], nil))
-
-	// When the result is nil that means the writer has not had the
-	// opportunity to write a value into the diode. This value must be ignored
-	// and the read head must not increment.
-	if result == nil {
-		return nil, false
-	}
+	data, _, ok = d.TryNextSeq()
+	return data, ok
+}
 
-	// When the seq value is less than the current read index that means a
-	// value was read from idx that was previously written but has since has
-	// been dropped. This value must be ignored and the read head must not
-	// increment.
-	//
-	// The simulation for this scenario assumes the fast forward occurred as
-	// detailed below.
-	//
-	// 5. The reader reads again getting seq 5. It then reads again expecting
-	//    seq 6 but gets seq 2. This is a read of a stale value that was
-	//    effectively "dropped" so the read fails and the read head stays put.
-	//    `| 4 | 5 | 2 | 3 |` r: 7, w: 6
-	//
-	if result.seq < d.readIndex {
-		return nil, false
+// TryNextSeq is like TryNext, but also returns the sequence number the data
+// was written with. Sequence numbers start at 0 and grow by one with each
+// Set, so a gap between two reads is the number of values that were lost.
+func (d *OneToOne) TryNextSeq() (data GenericDataType, seq uint64, ok bool) {
+	result, ok := d.next()
+	if !ok {
+		return nil, 0, false
 	}
 
-	// When the seq value is greater than the current read index that means a
-	// value was read from idx that overwrote the value that was expected to
-	// be at this idx. This happens when the writer has lapped the reader. The
-	// reader needs to catch up to the writer so it moves its write head to
-	// the new seq, effectively dropping the messages that were not read in
-	// between the two values.
-	//
-	// Here is a simulation of this scenario:
-	//
-	// 1. Both the read and write heads start at 0.
-	//    `| nil | nil | nil | nil |` r: 0, w: 0
-	// 2. The writer fills the buffer.
-	//    `| 0 | 1 | 2 | 3 |` r: 0, w: 4
-	// 3. The writer laps the read head.
-	//    `| 4 | 5 | 2 | 3 |` r: 0, w: 6
-	// 4. The reader reads the first value, expecting a seq of 0 but reads 4,
-	//    this forces the reader to fast forward to 5.
-	//    `| 4 | 5 | 2 | 3 |` r: 5, w: 6
-	//
-	if result.seq > d.readIndex {
-		dropped := result.seq - d.readIndex
-		d.readIndex = result.seq
-		d.alerter.Alert(int(dropped)) // nolint:gosec
+	return result.data, result.seq, true
+}
+
+// Len returns the approximate number of values that were not read yet. It is
+// at most Cap. It is safe to call from any go-routine.
+func (d *OneToOne) Len() int {
+	return d.len(atomic.LoadUint64(&d.writeIndex))
+}
+
+// Cap returns the size of the ring buffer, which is a power of two.
+func (d *OneToOne) Cap() int {
+	return len(d.buffer)
+}
+
+// Reset discards all values that were not read yet and reports them to the
+// alerter as dropped with the DropReset reason. It is safe to call while
+// writers keep writing, but it must be called by the reader: the alerter is
+// invoked on the calling go-routine.
+func (d *OneToOne) Reset() {
+	d.reset(atomic.LoadUint64(&d.writeIndex))
+}
+
+// Peek returns the data the next TryNext would return without consuming it.
+// If there is no data available, it will return (nil, false). It is meant to
+// be used by the reader. From other go-routines the result is best effort.
+func (d *OneToOne) Peek() (data GenericDataType, ok bool) {
+	result, ok := d.peek()
+	if !ok {
+		return nil, false
 	}
 
-	// Only increment read index if a regular read occurred (where seq was
-	// equal to readIndex) or a value was read that caused a fast forward
-	// (where seq was greater than readIndex).
-	d.readIndex++
 	return result.data, true
 }
+
+// Snapshot returns a best effort copy of the unread data ordered by sequence
+// number, without consuming it. It is safe to call from any go-routine. The
+// data is shared with the reader and must not be modified.
+func (d *OneToOne) Snapshot() []SnapshotEntry {
+	return d.snapshot(atomic.LoadUint64(&d.writeIndex))
+}
+
+// Stats returns a snapshot of the diode's counters. It is safe to call from
+// any go-routine.
+func (d *OneToOne) Stats() Stats {
+	return d.stats(atomic.LoadUint64(&d.writeIndex))
+}
@@ -18,7 +18,7 @@ var _ = Describe("OneToOne", func() {
 	BeforeEach(func() {
 		spy = newSpyAlerter()
 
-		d = diodes.NewOneToOne(5, spy)
+		d = diodes.NewOneToOne(4, spy)
 
 		data = []byte("some-data")
 		d.Set(diodes.GenericDataType(&data))
@@ -55,9 +55,25 @@ var _ = Describe("OneToOne", func() {
 			})
 		})
 
+		Describe("TryNextSeq()", func() {
+			It("returns the sequence numbers", func() {
+				result, seq, ok := d.TryNextSeq()
+				Expect(ok).To(BeTrue())
+				Expect(*(*[]byte)(result)).To(Equal(data))
+				Expect(seq).To(BeZero())
+
+				_, seq, ok = d.TryNextSeq()
+				Expect(ok).To(BeTrue())
+				Expect(seq).To(Equal(uint64(1)))
+
+				_, _, ok = d.TryNextSeq()
+				Expect(ok).To(BeFalse())
+			})
+		})
+
 		Context("buffer size exceeded", func() {
 			BeforeEach(func() {
-				for i := 0; i < 4; i++ {
+				for i := 0; i < 3; i++ {
 					d.Set(diodes.GenericDataType(&secondData))
 				}
 			})
@@ -67,21 +83,26 @@ var _ = Describe("OneToOne", func() {
 				Expect(*(*[]byte)(data)).To(Equal(secondData))
 			})
 
+			It("skips the sequence numbers of dropped points", func() {
+				_, seq, _ := d.TryNextSeq()
+				Expect(seq).To(Equal(uint64(4)))
+			})
+
 			It("alerts for each dropped point", func() {
 				d.TryNext()
-				Expect(spy.AlertInput.Missed).To(Receive(Equal(5)))
+				Expect(spy.AlertInput.Missed).To(Receive(Equal(4)))
 			})
 
 			It("it updates the read index", func() {
 				d.TryNext()
-				Expect(spy.AlertInput.Missed).To(Receive(Equal(5)))
+				Expect(spy.AlertInput.Missed).To(Receive(Equal(4)))
 
 				for i := 0; i < 6; i++ {
 					d.Set(diodes.GenericDataType(&secondData))
 				}
 
 				d.TryNext()
-				Expect(spy.AlertInput.Missed).To(Receive(Equal(5)))
+				Expect(spy.AlertInput.Missed).To(Receive(Equal(4)))
 			})
 
 			Context("read catches up with write", func() {
@@ -98,14 +119,14 @@ var _ = Describe("OneToOne", func() {
 
 			Context("writer laps reader", func() {
 				BeforeEach(func() {
-					for i := 0; i < 5; i++ {
+					for i := 0; i < 4; i++ {
 						d.Set(diodes.GenericDataType(&secondData))
 					}
 					d.TryNext()
 				})
 
 				It("sends an alert for each set", func() {
-					Expect(spy.AlertInput.Missed).To(Receive(Equal(10)))
+					Expect(spy.AlertInput.Missed).To(Receive(Equal(8)))
 				})
 			})
 
@@ -0,0 +1,95 @@
+package diodes
+
+import "time"
+
+// Clock is used by the diodes to read the current time.
+type Clock interface {
+	Now() time.Time
+}
+
+type systemClock struct{}
+
+func (systemClock) Now() time.Time {
+	return time.Now()
+}
+
+// DiodeConfigOption can be used to setup the OneToOne and ManyToOne diodes.
+type DiodeConfigOption func(*diodeConfig)
+
+type diodeConfig struct {
+	clock       Clock
+	maxAge      time.Duration
+	latency     bool
+	writerDrops bool
+	tagDrops    bool
+}
+
+// WithMaxAge stamps each value with the time it was written. Values that are
+// older than maxAge by the time the reader gets to them are skipped and
+// reported to the alerter as dropped with the DropExpired reason. The default
+// is to never expire values.
+func WithMaxAge(maxAge time.Duration) DiodeConfigOption {
+	return DiodeConfigOption(func(c *diodeConfig) {
+		c.maxAge = maxAge
+	})
+}
+
+// WithClock sets the clock used to stamp and expire values. The default is
+// the system clock.
+func WithClock(clock Clock) DiodeConfigOption {
+	return DiodeConfigOption(func(c *diodeConfig) {
+		c.clock = clock
+	})
+}
+
+// WithLatencyHistogram stamps each value with the time it was written and
+// records how long it waited in the diode once it is read. The latencies are
+// available in the Latency histogram of the diode's Stats.
+func WithLatencyHistogram() DiodeConfigOption {
+	return DiodeConfigOption(func(c *diodeConfig) {
+		c.latency = true
+	})
+}
+
+// WithWriterDropDetection makes writers check whether they overwrote a value
+// that was not read yet. Such values are counted in the Overwritten field of
+// the diode's Stats as soon as they are overwritten, even when the reader is
+// blocked. The alerter is still only invoked by the reader.
+func WithWriterDropDetection() DiodeConfigOption {
+	return DiodeConfigOption(func(c *diodeConfig) {
+		c.writerDrops = true
+	})
+}
+
+// WithTagDrops counts dropped values per Tag. A value counts as dropped when
+// a writer overwrites it before it was read, or when the reader skips it
+// because it was passed over or expired. The counts are available in the
+// TagDrops field of the diode's Stats and are reported to a TagAlerter. Values
+// written with Set have the tag 0.
+func WithTagDrops() DiodeConfigOption {
+	return DiodeConfigOption(func(c *diodeConfig) {
+		c.tagDrops = true
+	})
+}
+
+func newDiodeConfig(opts []DiodeConfigOption) diodeConfig {
+	c := diodeConfig{
+		clock: systemClock{},
+	}
+
+	for _, o := range opts {
+		o(&c)
+	}
+
+	return c
+}
+
+// stamp returns the current time in nanoseconds if values need to be
+// stamped, or zero otherwise.
+func (c *diodeConfig) stamp() int64 {
+	if c.maxAge <= 0 && !c.latency {
+		return 0
+	}
+
+	return c.clock.Now().UnixNano()
+}
@@ -0,0 +1,234 @@
+package diodes_test
+
+import (
+	"sync"
+	"time"
+
+	"code.cloudfoundry.org/go-diodes"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("WithMaxAge", func() {
+	var (
+		clock *fakeClock
+		spy   *spyReasonAlerter
+	)
+
+	BeforeEach(func() {
+		clock = newFakeClock()
+		spy = newSpyReasonAlerter()
+	})
+
+	for _, storage := range storageTypes {
+		constructor := storage.constructor
+
+		Context(storage.name, func() {
+			var d diodes.Diode
+
+			BeforeEach(func() {
+				d = constructor(4, spy, diodes.WithMaxAge(time.Minute), diodes.WithClock(clock))
+			})
+
+			It("returns values younger than the max age", func() {
+				data := 1
+				d.Set(diodes.GenericDataType(&data))
+				clock.Advance(time.Minute)
+
+				result, ok := d.TryNext()
+				Expect(ok).To(BeTrue())
+				Expect(*(*int)(result)).To(Equal(1))
+				Expect(spy.Missed).ToNot(Receive())
+			})
+
+			It("skips values older than the max age and reports them as expired", func() {
+				for i := 0; i < 3; i++ {
+					j := i
+					d.Set(diodes.GenericDataType(&j))
+				}
+				clock.Advance(2 * time.Minute)
+				j := 3
+				d.Set(diodes.GenericDataType(&j))
+
+				result, ok := d.TryNext()
+				Expect(ok).To(BeTrue())
+				Expect(*(*int)(result)).To(Equal(3))
+				Expect(spy.Missed).To(Receive(Equal(3)))
+				Expect(spy.Reasons).To(Receive(Equal(diodes.DropExpired)))
+			})
+
+			It("returns false when every value has expired", func() {
+				data := 1
+				d.Set(diodes.GenericDataType(&data))
+				d.Set(diodes.GenericDataType(&data))
+				clock.Advance(2 * time.Minute)
+
+				_, ok := d.TryNext()
+				Expect(ok).To(BeFalse())
+				Expect(spy.Missed).To(Receive(Equal(2)))
+				Expect(spy.Reasons).To(Receive(Equal(diodes.DropExpired)))
+
+				d.Set(diodes.GenericDataType(&data))
+				_, ok = d.TryNext()
+				Expect(ok).To(BeTrue())
+			})
+
+			It("reports overwritten values with their own reason", func() {
+				for i := 0; i < 6; i++ {
+					j := i
+					d.Set(diodes.GenericDataType(&j))
+				}
+
+				d.TryNext()
+				Expect(spy.Missed).To(Receive(Equal(4)))
+				Expect(spy.Reasons).To(Receive(Equal(diodes.DropOverwritten)))
+			})
+
+			It("reports expired values to a plain Alerter", func() {
+				plain := newSpyAlerter()
+				d = constructor(5, plain, diodes.WithMaxAge(time.Minute), diodes.WithClock(clock))
+				data := 1
+				d.Set(diodes.GenericDataType(&data))
+				clock.Advance(2 * time.Minute)
+
+				d.TryNext()
+				Expect(plain.AlertInput.Missed).To(Receive(Equal(1)))
+			})
+		})
+	}
+})
+
+var _ = Describe("WithWriterDropDetection", func() {
+	for _, storage := range storageTypes {
+		constructor := storage.constructor
+
+		Context(storage.name, func() {
+			var (
+				d   diodes.Diode
+				spy *spyAlerter
+			)
+
+			BeforeEach(func() {
+				spy = newSpyAlerter()
+				d = constructor(4, spy, diodes.WithWriterDropDetection())
+			})
+
+			set := func(n int) {
+				data := 1
+				for i := 0; i < n; i++ {
+					d.Set(diodes.GenericDataType(&data))
+				}
+			}
+
+			overwritten := func() uint64 {
+				return d.(diodes.StatsReporter).Stats().Overwritten
+			}
+
+			It("counts unread values as soon as they are overwritten", func() {
+				set(6)
+
+				Expect(overwritten()).To(Equal(uint64(2)))
+				Expect(spy.AlertCalled).ToNot(Receive())
+			})
+
+			It("does not count values the reader already skipped", func() {
+				set(6)
+				d.TryNext()
+				Expect(spy.AlertInput.Missed).To(Receive(Equal(4)))
+
+				set(2)
+				Expect(overwritten()).To(Equal(uint64(2)))
+			})
+
+			It("does not count values that were read", func() {
+				set(4)
+				for i := 0; i < 4; i++ {
+					d.TryNext()
+				}
+				set(4)
+
+				Expect(overwritten()).To(BeZero())
+			})
+
+			It("is disabled by default", func() {
+				d = constructor(4, spy)
+				set(6)
+
+				Expect(overwritten()).To(BeZero())
+			})
+		})
+	}
+})
+
+var _ = Describe("DropReason", func() {
+	It("has a readable name", func() {
+		Expect(diodes.DropOverwritten.String()).To(Equal("overwritten"))
+		Expect(diodes.DropExpired.String()).To(Equal("expired"))
+		Expect(diodes.DropReset.String()).To(Equal("reset"))
+		Expect(diodes.DropReason(-1).String()).To(Equal("unknown"))
+	})
+})
+
+// storageTypes lists the ring buffer diodes that accept DiodeConfigOptions.
+var storageTypes = []struct {
+	name        string
+	constructor func(int, diodes.Alerter, ...diodes.DiodeConfigOption) diodes.Diode
+}{
+	{
+		name: "OneToOne",
+		constructor: func(size int, a diodes.Alerter, opts ...diodes.DiodeConfigOption) diodes.Diode {
+			return diodes.NewOneToOne(size, a, opts...)
+		},
+	},
+	{
+		name: "ManyToOne",
+		constructor: func(size int, a diodes.Alerter, opts ...diodes.DiodeConfigOption) diodes.Diode {
+			return diodes.NewManyToOne(size, a, opts...)
+		},
+	},
+}
+
+type fakeClock struct {
+	mu  sync.Mutex
+	now time.Time
+}
+
+func newFakeClock() *fakeClock {
+	return &fakeClock{
+		now: time.Unix(1000, 0),
+	}
+}
+
+func (c *fakeClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+func (c *fakeClock) Advance(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.now = c.now.Add(d)
+}
+
+type spyReasonAlerter struct {
+	Missed  chan int
+	Reasons chan diodes.DropReason
+}
+
+func newSpyReasonAlerter() *spyReasonAlerter {
+	return &spyReasonAlerter{
+		Missed:  make(chan int, 100),
+		Reasons: make(chan diodes.DropReason, 100),
+	}
+}
+
+func (s *spyReasonAlerter) Alert(missed int) {
+	s.AlertReason(missed, diodes.DropOverwritten)
+}
+
+func (s *spyReasonAlerter) AlertReason(missed int, reason diodes.DropReason) {
+	s.Missed <- missed
+	s.Reasons <- reason
+}
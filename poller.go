@@ -11,6 +11,14 @@ type Diode interface {
 	TryNext() (GenericDataType, bool)
 }
 
+// SeqDiode is a diode that can report the sequence number of each value it
+// returns. Sequence numbers start at 0 and grow by one with each write, so a
+// reader can tell how many values were lost between two reads.
+type SeqDiode interface {
+	Diode
+	TryNextSeq() (data GenericDataType, seq uint64, ok bool)
+}
+
 // Poller will poll a diode until a value is available.
 type Poller struct {
 	Diode
@@ -70,6 +78,29 @@ func (p *Poller) Next() GenericDataType {
 	}
 }
 
+// NextSeq is like Next, but also returns the sequence number of the data. It
+// returns (nil, 0, false) if the context is done, and right away if the
+// wrapped diode does not implement SeqDiode.
+func (p *Poller) NextSeq() (data GenericDataType, seq uint64, ok bool) {
+	d, ok := p.Diode.(SeqDiode)
+	if !ok {
+		return nil, 0, false
+	}
+
+	for {
+		data, seq, ok = d.TryNextSeq()
+		if !ok {
+			if p.isDone() {
+				return nil, 0, false
+			}
+
+			time.Sleep(p.interval)
+			continue
+		}
+		return data, seq, true
+	}
+}
+
 func (p *Poller) isDone() bool {
 	select {
 	case <-p.ctx.Done():
@@ -78,3 +109,13 @@ func (p *Poller) isDone() bool {
 		return false
 	}
 }
+
+// Stats returns the Stats of the wrapped diode. If the wrapped diode does not
+// implement StatsReporter, the zero value is returned.
+func (p *Poller) Stats() Stats {
+	if s, ok := p.Diode.(StatsReporter); ok {
+		return s.Stats()
+	}
+
+	return Stats{}
+}
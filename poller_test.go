@@ -40,6 +40,25 @@ var _ = Describe("Poller", func() {
 		Expect(*(*[]byte)(p.Next())).To(Equal([]byte("a")))
 	})
 
+	It("returns the sequence number with NextSeq()", func() {
+		d := diodes.NewOneToOne(4, nil)
+		p = diodes.NewPoller(d, diodes.WithPollingInterval(time.Millisecond))
+		for _, s := range []string{"a", "b", "c"} {
+			d.Set(diodes.GenericDataType(&s))
+		}
+		d.TryNext()
+
+		data, seq, ok := p.NextSeq()
+		Expect(ok).To(BeTrue())
+		Expect(*(*string)(data)).To(Equal("b"))
+		Expect(seq).To(Equal(uint64(1)))
+	})
+
+	It("returns false from NextSeq() if the diode does not report sequence numbers", func() {
+		_, _, ok := p.NextSeq()
+		Expect(ok).To(BeFalse())
+	})
+
 	It("cancels Next() with context", func() {
 		ctx, cancel := context.WithCancel(context.Background())
 		p = diodes.NewPoller(spy, diodes.WithPollingContext(ctx))
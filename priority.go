@@ -0,0 +1,69 @@
+package diodes
+
+// Lane configures a single lane of a PriorityDiode.
+type Lane struct {
+	// Size is the capacity of the lane's ring buffer. It is rounded up to the
+	// next power of two.
+	Size int
+
+	// Alerter is invoked when values in this lane are dropped. A nil can be
+	// used to ignore alerts.
+	Alerter Alerter
+}
+
+// PriorityDiode is made of several lanes, each backed by its own ManyToOne
+// diode. Lanes are ordered by priority: lane 0 is the highest priority and is
+// always drained first. Since each lane has its own ring buffer, writes to a
+// lower priority lane can never overwrite data in a higher priority lane.
+// It is optimal for many writers and a single reader. It is not thread safe
+// for multiple readers.
+type PriorityDiode struct {
+	lanes []*ManyToOne
+}
+
+// NewPriorityDiode creates a new diode with the given lanes, ordered from
+// highest to lowest priority. Each lane's alerter is invoked on the read's
+// go-routine when it notices that data in that lane was dropped.
+func NewPriorityDiode(lanes ...Lane) *PriorityDiode {
+	if len(lanes) == 0 {
+		panic("diodes: a PriorityDiode requires at least one lane")
+	}
+
+	d := &PriorityDiode{
+		lanes: make([]*ManyToOne, len(lanes)),
+	}
+	for i, l := range lanes {
+		d.lanes[i] = NewManyToOne(l.Size, l.Alerter)
+	}
+
+	return d
+}
+
+// Lanes returns the number of lanes.
+func (d *PriorityDiode) Lanes() int {
+	return len(d.lanes)
+}
+
+// SetLane sets the data in the next slot of the given lane. It panics if the
+// lane does not exist.
+func (d *PriorityDiode) SetLane(lane int, data GenericDataType) {
+	d.lanes[lane].Set(data)
+}
+
+// Set sets the data in the lowest priority lane.
+func (d *PriorityDiode) Set(data GenericDataType) {
+	d.lanes[len(d.lanes)-1].Set(data)
+}
+
+// TryNext will attempt to read from the highest priority lane that has data
+// available. If there is no data available in any lane, it will return
+// (nil, false).
+func (d *PriorityDiode) TryNext() (data GenericDataType, ok bool) {
+	for _, l := range d.lanes {
+		if data, ok := l.TryNext(); ok {
+			return data, true
+		}
+	}
+
+	return nil, false
+}
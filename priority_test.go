@@ -0,0 +1,86 @@
+package diodes_test
+
+import (
+	"code.cloudfoundry.org/go-diodes"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("PriorityDiode", func() {
+	var (
+		d        *diodes.PriorityDiode
+		critical *spyAlerter
+		bulk     *spyAlerter
+	)
+
+	BeforeEach(func() {
+		critical = newSpyAlerter()
+		bulk = newSpyAlerter()
+		d = diodes.NewPriorityDiode(
+			diodes.Lane{Size: 4, Alerter: critical},
+			diodes.Lane{Size: 2, Alerter: bulk},
+		)
+	})
+
+	It("reports the number of lanes", func() {
+		Expect(d.Lanes()).To(Equal(2))
+	})
+
+	It("panics without lanes", func() {
+		Expect(func() {
+			diodes.NewPriorityDiode()
+		}).To(Panic())
+	})
+
+	It("returns false when no lane has data", func() {
+		_, ok := d.TryNext()
+		Expect(ok).To(BeFalse())
+	})
+
+	It("drains higher priority lanes first", func() {
+		low, high := []byte("low"), []byte("high")
+		d.Set(diodes.GenericDataType(&low))
+		d.SetLane(0, diodes.GenericDataType(&high))
+
+		data, ok := d.TryNext()
+		Expect(ok).To(BeTrue())
+		Expect(*(*[]byte)(data)).To(Equal(high))
+
+		data, ok = d.TryNext()
+		Expect(ok).To(BeTrue())
+		Expect(*(*[]byte)(data)).To(Equal(low))
+	})
+
+	It("does not let bulk traffic evict critical traffic", func() {
+		high := []byte("high")
+		d.SetLane(0, diodes.GenericDataType(&high))
+		for i := 0; i < 10; i++ {
+			j := i
+			d.SetLane(1, diodes.GenericDataType(&j))
+		}
+
+		data, _ := d.TryNext()
+		Expect(*(*[]byte)(data)).To(Equal(high))
+		Expect(critical.AlertCalled).ToNot(Receive())
+	})
+
+	It("reports drops per lane", func() {
+		for i := 0; i < 5; i++ {
+			j := i
+			d.SetLane(1, diodes.GenericDataType(&j))
+		}
+
+		data, _ := d.TryNext()
+		Expect(*(*int)(data)).To(Equal(4))
+		Expect(bulk.AlertInput.Missed).To(Receive(Equal(4)))
+		Expect(critical.AlertCalled).ToNot(Receive())
+	})
+
+	It("panics when setting an unknown lane", func() {
+		data := 1
+		Expect(func() {
+			d.SetLane(2, diodes.GenericDataType(&data))
+		}).To(Panic())
+	})
+})
@@ -0,0 +1,119 @@
+package diodes
+
+import (
+	"sync/atomic"
+)
+
+// Producer is a handle for writing to a ManyToOne diode from a single
+// go-routine. The values a Producer writes are read in the order they were
+// written: the reader never returns a value of a Producer after a value the
+// same Producer wrote later. Values can still be dropped when the reader
+// falls behind, and the Producer counts how many of its values were dropped.
+//
+// A Producer must not be used by more than one go-routine at a time. Use one
+// Producer per writing go-routine.
+type Producer struct {
+	d     *ManyToOne
+	wake  func()
+	alert func(missed uint64)
+
+	// seq is only touched by the writer.
+	seq uint64
+
+	// read is only touched by the reader.
+	read    uint64
+	dropped atomic.Uint64
+
+	// pending counts the lost values the reader has not reported yet. The
+	// Producer is linked into the ring's lossy list by next while pending
+	// is not zero.
+	pending atomic.Uint64
+	next    *Producer
+}
+
+// Producer returns a new handle for writing to the diode in order.
+func (d *ManyToOne) Producer() *Producer {
+	return &Producer{d: d}
+}
+
+// Producer returns a new handle for writing to the wrapped diode in order.
+// Unlike the ManyToOne's Producer, its writes wake up the Waiter's reader.
+// It panics if the wrapped diode is not a ManyToOne.
+func (w *Waiter) Producer() *Producer {
+	d, ok := w.Diode.(*ManyToOne)
+	if !ok {
+		panic("diodes: Producer requires a Waiter that wraps a ManyToOne")
+	}
+
+	p := d.Producer()
+	p.wake = w.broadcast
+	return p
+}
+
+// Set sets the data in the next slot of the diode's ring buffer.
+func (p *Producer) Set(data GenericDataType) {
+	p.seq++
+	p.d.set(data, 0, p, p.seq)
+
+	if p.wake != nil {
+		p.wake()
+	}
+}
+
+// Dropped returns the number of the Producer's values that were dropped. A
+// value is counted as soon as it is overwritten, skipped by the reader or
+// removed by Reset. It is safe to call from any go-routine.
+func (p *Producer) Dropped() uint64 {
+	return p.dropped.Load()
+}
+
+// deliver is invoked by the reader with the sequence number of a value of
+// the Producer and reports whether the value may be delivered. A value that
+// is not newer than the last delivered one is discarded to keep the
+// Producer's values in order.
+func (p *Producer) deliver(pseq uint64) bool {
+	if pseq <= p.read {
+		return false
+	}
+	p.read = pseq
+
+	return true
+}
+
+// lostProducer counts a lost value of the producer. The first value lost
+// since the reader last reported links the producer into the ring's lossy
+// list. It is invoked by both writers and the reader.
+func (r *ring) lostProducer(p *Producer) {
+	p.dropped.Add(1)
+	if p.pending.Add(1) != 1 {
+		return
+	}
+
+	for {
+		head := r.lossy.Load()
+		p.next = head
+		if r.lossy.CompareAndSwap(head, p) {
+			return
+		}
+	}
+}
+
+// alertProducers reports the values lost since the last invocation to the
+// alert of each producer in the lossy list. It is invoked by the reader.
+func (r *ring) alertProducers() {
+	// Most reads have nothing to report. Loading first keeps them from
+	// writing to the shared cache line.
+	if r.lossy.Load() == nil {
+		return
+	}
+
+	for p := r.lossy.Swap(nil); p != nil; {
+		// next must be read before pending is reset, since a writer may
+		// link the producer into the list again after that.
+		next := p.next
+		if missed := p.pending.Swap(0); missed > 0 && p.alert != nil {
+			p.alert(missed)
+		}
+		p = next
+	}
+}
@@ -0,0 +1,135 @@
+package diodes_test
+
+import (
+	"fmt"
+	"sync"
+
+	"code.cloudfoundry.org/go-diodes"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Producer", func() {
+	It("writes to the diode", func() {
+		d := diodes.NewManyToOne(4, nil)
+		p := d.Producer()
+
+		data := 1
+		p.Set(diodes.GenericDataType(&data))
+
+		result, ok := d.TryNext()
+		Expect(ok).To(BeTrue())
+		Expect(*(*int)(result)).To(Equal(1))
+	})
+
+	It("counts its dropped values", func() {
+		d := diodes.NewManyToOne(2, nil)
+		p := d.Producer()
+		other := d.Producer()
+
+		for i := 0; i < 5; i++ {
+			data := i
+			p.Set(diodes.GenericDataType(&data))
+		}
+		data := 5
+		other.Set(diodes.GenericDataType(&data))
+
+		result, ok := d.TryNext()
+		Expect(ok).To(BeTrue())
+		Expect(*(*int)(result)).To(Equal(4))
+		Expect(p.Dropped()).To(Equal(uint64(4)))
+		Expect(other.Dropped()).To(BeZero())
+	})
+
+	It("wakes up the reader of a Waiter", func() {
+		w := diodes.NewWaiter(diodes.NewManyToOne(4, nil))
+		p := w.Producer()
+
+		next := make(chan int)
+		go func() {
+			next <- *(*int)(w.Next())
+		}()
+
+		data := 7
+		p.Set(diodes.GenericDataType(&data))
+		Eventually(next).Should(Receive(Equal(7)))
+	})
+
+	It("panics for a Waiter that does not wrap a ManyToOne", func() {
+		w := diodes.NewWaiter(diodes.NewOneToOne(4, nil))
+		Expect(func() { w.Producer() }).To(Panic())
+	})
+
+	It("keeps the values of each producer in order under contention", func() {
+		const (
+			producers = 8
+			writes    = 10000
+		)
+
+		type value struct {
+			producer int
+			n        uint64
+		}
+
+		d := diodes.NewManyToOne(64, nil)
+		handles := make([]*diodes.Producer, producers)
+		for i := range handles {
+			handles[i] = d.Producer()
+		}
+
+		var wg sync.WaitGroup
+		for i, p := range handles {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				for n := uint64(1); n <= writes; n++ {
+					p.Set(diodes.GenericDataType(&value{producer: i, n: n}))
+				}
+			}()
+		}
+
+		done := make(chan struct{})
+		go func() {
+			wg.Wait()
+			close(done)
+		}()
+
+		var (
+			last      [producers]uint64
+			delivered [producers]uint64
+			reorders  []string
+		)
+		read := func() bool {
+			data, ok := d.TryNext()
+			if !ok {
+				return false
+			}
+
+			v := (*value)(data)
+			if v.n <= last[v.producer] {
+				reorders = append(reorders, fmt.Sprintf("producer %d: read %d after %d", v.producer, v.n, last[v.producer]))
+			}
+			last[v.producer] = v.n
+			delivered[v.producer]++
+			return true
+		}
+
+	loop:
+		for {
+			select {
+			case <-done:
+				break loop
+			default:
+				read()
+			}
+		}
+		for read() {
+		}
+
+		Expect(reorders).To(BeEmpty())
+		for i, p := range handles {
+			Expect(delivered[i]+p.Dropped()).To(BeNumerically("<=", writes), fmt.Sprintf("producer %d", i))
+		}
+	})
+})
@@ -0,0 +1,223 @@
+// Package prometheus renders the Stats of diodes in the Prometheus text
+// exposition format without depending on the Prometheus client library.
+package prometheus
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+
+	"code.cloudfoundry.org/go-diodes"
+)
+
+// ContentType is the content type of the Prometheus text exposition format.
+const ContentType = "text/plain; version=0.0.4; charset=utf-8"
+
+// Exporter holds named diodes and renders their Stats. It is an
+// http.Handler and is safe to use from several go-routines.
+type Exporter struct {
+	mu     sync.RWMutex
+	diodes map[string]diodes.StatsReporter
+}
+
+// NewExporter returns an Exporter without any diodes.
+func NewExporter() *Exporter {
+	return &Exporter{
+		diodes: make(map[string]diodes.StatsReporter),
+	}
+}
+
+// Register adds a diode under the given name. The name is rendered as the
+// value of the "diode" label. It returns an error if the name is already
+// taken.
+func (e *Exporter) Register(name string, d diodes.StatsReporter) error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	if _, ok := e.diodes[name]; ok {
+		return fmt.Errorf("diode %q is already registered", name)
+	}
+	e.diodes[name] = d
+
+	return nil
+}
+
+// Unregister removes the diode with the given name.
+func (e *Exporter) Unregister(name string) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	delete(e.diodes, name)
+}
+
+// ServeHTTP renders the Stats of every registered diode.
+func (e *Exporter) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", ContentType)
+	e.WriteTo(w) //nolint:errcheck
+}
+
+// WriteTo renders the Stats of every registered diode to w.
+func (e *Exporter) WriteTo(w io.Writer) (int64, error) {
+	names, stats := e.snapshot()
+
+	cw := &countingWriter{w: bufio.NewWriter(w)}
+	for _, m := range metrics {
+		fmt.Fprintf(cw, "# HELP %s %s\n", m.name, m.help)
+		fmt.Fprintf(cw, "# TYPE %s %s\n", m.name, m.kind)
+		for i, name := range names {
+			m.write(cw, m.name, label(name), stats[i])
+		}
+	}
+
+	if err := cw.w.Flush(); err != nil {
+		return cw.n, err
+	}
+
+	return cw.n, cw.err
+}
+
+func (e *Exporter) snapshot() ([]string, []diodes.Stats) {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+
+	names := make([]string, 0, len(e.diodes))
+	for name := range e.diodes {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	stats := make([]diodes.Stats, len(names))
+	for i, name := range names {
+		stats[i] = e.diodes[name].Stats()
+	}
+
+	return names, stats
+}
+
+type metric struct {
+	name  string
+	help  string
+	kind  string
+	write func(w io.Writer, name, labels string, s diodes.Stats)
+}
+
+var metrics = []metric{
+	{
+		name: "diode_writes_total",
+		help: "Number of values written to the diode.",
+		kind: "counter",
+		write: func(w io.Writer, name, labels string, s diodes.Stats) {
+			fmt.Fprintf(w, "%s{%s} %d\n", name, labels, s.Writes)
+		},
+	},
+	{
+		name: "diode_reads_total",
+		help: "Number of values read from the diode.",
+		kind: "counter",
+		write: func(w io.Writer, name, labels string, s diodes.Stats) {
+			fmt.Fprintf(w, "%s{%s} %d\n", name, labels, s.Reads)
+		},
+	},
+	{
+		name: "diode_dropped_total",
+		help: "Number of values dropped by the diode before they were read.",
+		kind: "counter",
+		write: func(w io.Writer, name, labels string, s diodes.Stats) {
+			fmt.Fprintf(w, "%s{%s,reason=%q} %d\n", name, labels, diodes.DropOverwritten, s.Dropped-s.Reset)
+			fmt.Fprintf(w, "%s{%s,reason=%q} %d\n", name, labels, diodes.DropReset, s.Reset)
+			fmt.Fprintf(w, "%s{%s,reason=%q} %d\n", name, labels, diodes.DropExpired, s.Expired)
+		},
+	},
+	{
+		name: "diode_overwritten_total",
+		help: "Number of unread values overwritten by writers, counted when they were overwritten.",
+		kind: "counter",
+		write: func(w io.Writer, name, labels string, s diodes.Stats) {
+			fmt.Fprintf(w, "%s{%s} %d\n", name, labels, s.Overwritten)
+		},
+	},
+	{
+		name: "diode_tag_dropped_total",
+		help: "Number of values dropped by the diode per tag.",
+		kind: "counter",
+		write: func(w io.Writer, name, labels string, s diodes.Stats) {
+			tags := make([]int, 0, len(s.TagDrops))
+			for tag := range s.TagDrops {
+				tags = append(tags, int(tag))
+			}
+			sort.Ints(tags)
+
+			for _, tag := range tags {
+				fmt.Fprintf(w, "%s{%s,tag=\"%d\"} %d\n", name, labels, tag, s.TagDrops[diodes.Tag(tag)])
+			}
+		},
+	},
+	{
+		name: "diode_collisions_total",
+		help: "Number of write collisions in the diode.",
+		kind: "counter",
+		write: func(w io.Writer, name, labels string, s diodes.Stats) {
+			fmt.Fprintf(w, "%s{%s} %d\n", name, labels, s.Collisions)
+		},
+	},
+	{
+		name: "diode_lag",
+		help: "Approximate number of values in the diode that were not read yet.",
+		kind: "gauge",
+		write: func(w io.Writer, name, labels string, s diodes.Stats) {
+			fmt.Fprintf(w, "%s{%s} %d\n", name, labels, s.Len)
+		},
+	},
+	{
+		name: "diode_capacity",
+		help: "Size of the diode's ring buffer.",
+		kind: "gauge",
+		write: func(w io.Writer, name, labels string, s diodes.Stats) {
+			fmt.Fprintf(w, "%s{%s} %d\n", name, labels, s.Capacity)
+		},
+	},
+	{
+		name: "diode_latency_seconds",
+		help: "Time values spent in the diode before they were read.",
+		kind: "summary",
+		write: func(w io.Writer, name, labels string, s diodes.Stats) {
+			if s.Latency.Count == 0 {
+				return
+			}
+
+			for _, q := range []float64{0.5, 0.9, 0.99} {
+				fmt.Fprintf(w, "%s{%s,quantile=\"%g\"} %g\n", name, labels, q, s.Latency.Quantile(q).Seconds())
+			}
+			fmt.Fprintf(w, "%s_sum{%s} %g\n", name, labels, s.Latency.Sum.Seconds())
+			fmt.Fprintf(w, "%s_count{%s} %d\n", name, labels, s.Latency.Count)
+		},
+	},
+}
+
+var labelEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+func label(name string) string {
+	return `diode="` + labelEscaper.Replace(name) + `"`
+}
+
+// countingWriter keeps track of the bytes written and the first error.
+type countingWriter struct {
+	w   *bufio.Writer
+	n   int64
+	err error
+}
+
+func (c *countingWriter) Write(p []byte) (int, error) {
+	if c.err != nil {
+		return 0, c.err
+	}
+
+	n, err := c.w.Write(p)
+	c.n += int64(n)
+	c.err = err
+	return n, err
+}
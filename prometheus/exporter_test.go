@@ -0,0 +1,144 @@
+package prometheus_test
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+
+	"code.cloudfoundry.org/go-diodes"
+	"code.cloudfoundry.org/go-diodes/prometheus"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Exporter", func() {
+	var (
+		e      *prometheus.Exporter
+		server *httptest.Server
+	)
+
+	BeforeEach(func() {
+		e = prometheus.NewExporter()
+		server = httptest.NewServer(e)
+	})
+
+	AfterEach(func() {
+		server.Close()
+	})
+
+	scrape := func() string {
+		resp, err := http.Get(server.URL)
+		Expect(err).ToNot(HaveOccurred())
+		defer resp.Body.Close()
+
+		Expect(resp.StatusCode).To(Equal(http.StatusOK))
+		Expect(resp.Header.Get("Content-Type")).To(Equal(prometheus.ContentType))
+
+		body, err := io.ReadAll(resp.Body)
+		Expect(err).ToNot(HaveOccurred())
+		return string(body)
+	}
+
+	It("renders the metadata without diodes", func() {
+		body := scrape()
+		Expect(body).To(ContainSubstring("# TYPE diode_writes_total counter\n"))
+		Expect(body).To(ContainSubstring("# TYPE diode_lag gauge\n"))
+		Expect(body).ToNot(ContainSubstring("{"))
+	})
+
+	It("renders the counters of registered diodes", func() {
+		d := diodes.NewManyToOne(4, nil)
+		Expect(e.Register("egress", d)).To(Succeed())
+
+		data := 1
+		for i := 0; i < 6; i++ {
+			d.Set(diodes.GenericDataType(&data))
+		}
+		d.TryNext()
+
+		body := scrape()
+		Expect(body).To(ContainSubstring(`diode_writes_total{diode="egress"} 6` + "\n"))
+		Expect(body).To(ContainSubstring(`diode_reads_total{diode="egress"} 1` + "\n"))
+		Expect(body).To(ContainSubstring(`diode_dropped_total{diode="egress",reason="overwritten"} 4` + "\n"))
+		Expect(body).To(ContainSubstring(`diode_dropped_total{diode="egress",reason="expired"} 0` + "\n"))
+		Expect(body).To(ContainSubstring(`diode_overwritten_total{diode="egress"} 0` + "\n"))
+		Expect(body).To(ContainSubstring(`diode_collisions_total{diode="egress"} 0` + "\n"))
+		Expect(body).To(ContainSubstring(`diode_lag{diode="egress"} 1` + "\n"))
+		Expect(body).To(ContainSubstring(`diode_capacity{diode="egress"} 4` + "\n"))
+		Expect(body).ToNot(ContainSubstring("diode_latency_seconds{"))
+	})
+
+	It("renders values discarded by Reset with their own reason", func() {
+		d := diodes.NewManyToOne(4, nil)
+		Expect(e.Register("reset", d)).To(Succeed())
+
+		data := 1
+		for i := 0; i < 6; i++ {
+			d.Set(diodes.GenericDataType(&data))
+		}
+		d.TryNext()
+		d.Reset()
+
+		body := scrape()
+		Expect(body).To(ContainSubstring(`diode_dropped_total{diode="reset",reason="overwritten"} 4` + "\n"))
+		Expect(body).To(ContainSubstring(`diode_dropped_total{diode="reset",reason="reset"} 1` + "\n"))
+	})
+
+	It("renders the latency summary when it is recorded", func() {
+		d := diodes.NewOneToOne(4, nil, diodes.WithLatencyHistogram())
+		Expect(e.Register("ingress", diodes.NewPoller(d))).To(Succeed())
+
+		data := 1
+		d.Set(diodes.GenericDataType(&data))
+		d.TryNext()
+
+		body := scrape()
+		Expect(body).To(ContainSubstring(`diode_latency_seconds{diode="ingress",quantile="0.99"}`))
+		Expect(body).To(ContainSubstring(`diode_latency_seconds_count{diode="ingress"} 1` + "\n"))
+	})
+
+	It("renders the drops per tag when they are counted", func() {
+		d := diodes.NewOneToOne(1, nil, diodes.WithTagDrops())
+		Expect(e.Register("tagged", d)).To(Succeed())
+
+		data := 1
+		d.SetTagged(7, diodes.GenericDataType(&data))
+		d.SetTagged(2, diodes.GenericDataType(&data))
+		d.SetTagged(2, diodes.GenericDataType(&data))
+		d.SetTagged(2, diodes.GenericDataType(&data))
+
+		Expect(scrape()).To(ContainSubstring(
+			`diode_tag_dropped_total{diode="tagged",tag="2"} 2` + "\n" +
+				`diode_tag_dropped_total{diode="tagged",tag="7"} 1` + "\n",
+		))
+	})
+
+	It("sorts diodes by name", func() {
+		Expect(e.Register("b", diodes.NewOneToOne(1, nil))).To(Succeed())
+		Expect(e.Register("a", diodes.NewOneToOne(1, nil))).To(Succeed())
+
+		Expect(scrape()).To(ContainSubstring(
+			`diode_capacity{diode="a"} 1` + "\n" + `diode_capacity{diode="b"} 1` + "\n",
+		))
+	})
+
+	It("escapes label values", func() {
+		Expect(e.Register("a\"b\\c\nd", diodes.NewOneToOne(1, nil))).To(Succeed())
+
+		Expect(scrape()).To(ContainSubstring(`diode_capacity{diode="a\"b\\c\nd"} 1`))
+	})
+
+	It("rejects duplicate names", func() {
+		Expect(e.Register("a", diodes.NewOneToOne(1, nil))).To(Succeed())
+		Expect(e.Register("a", diodes.NewOneToOne(1, nil))).ToNot(Succeed())
+	})
+
+	It("unregisters diodes", func() {
+		Expect(e.Register("a", diodes.NewOneToOne(1, nil))).To(Succeed())
+		e.Unregister("a")
+
+		Expect(scrape()).ToNot(ContainSubstring(`diode="a"`))
+		Expect(e.Register("a", diodes.NewOneToOne(1, nil))).To(Succeed())
+	})
+})
@@ -0,0 +1,92 @@
+package registry
+
+import (
+	"encoding/json"
+	"html/template"
+	"net/http"
+	"sort"
+	"strings"
+)
+
+// Handler returns an http.Handler serving the Default registry.
+func Handler() http.Handler {
+	return Default
+}
+
+// sorters order entries for the sort query parameter. Numeric columns are
+// sorted in descending order so the worst diodes come first.
+var sorters = map[string]func(a, b Entry) bool{
+	"name":       func(a, b Entry) bool { return a.Name < b.Name },
+	"fill":       func(a, b Entry) bool { return a.Fill > b.Fill },
+	"drop_rate":  func(a, b Entry) bool { return a.DropRate > b.DropRate },
+	"dropped":    func(a, b Entry) bool { return a.Dropped > b.Dropped },
+	"collisions": func(a, b Entry) bool { return a.Collisions > b.Collisions },
+	"idle":       func(a, b Entry) bool { return a.ReaderIdle > b.ReaderIdle },
+}
+
+// ServeHTTP serves a page listing every registered diode. The page is
+// rendered as JSON when the format query parameter is "json" or the request
+// accepts application/json, and as HTML otherwise. The sort query parameter
+// orders the diodes by name (the default), fill, drop_rate, dropped,
+// collisions or idle.
+func (r *Registry) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	sortBy := req.URL.Query().Get("sort")
+	if sortBy == "" {
+		sortBy = "name"
+	}
+
+	less, ok := sorters[sortBy]
+	if !ok {
+		http.Error(w, "unknown sort: "+sortBy, http.StatusBadRequest)
+		return
+	}
+
+	entries := r.Snapshot()
+	sort.SliceStable(entries, func(i, j int) bool {
+		return less(entries[i], entries[j])
+	})
+
+	if req.URL.Query().Get("format") == "json" ||
+		strings.Contains(req.Header.Get("Accept"), "application/json") {
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(entries) //nolint:errcheck
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	page.Execute(w, entries) //nolint:errcheck
+}
+
+var page = template.Must(template.New("registry").Funcs(template.FuncMap{
+	"percent": func(f float64) float64 { return f * 100 },
+}).Parse(`<!DOCTYPE html>
+<html>
+<head><title>Diodes</title></head>
+<body>
+<table>
+<tr>
+<th><a href="?sort=name">Name</a></th>
+<th>Labels</th>
+<th>Capacity</th>
+<th><a href="?sort=fill">Fill</a></th>
+<th><a href="?sort=dropped">Dropped</a></th>
+<th><a href="?sort=drop_rate">Drop rate</a></th>
+<th><a href="?sort=collisions">Collisions</a></th>
+<th><a href="?sort=idle">Reader idle</a></th>
+</tr>
+{{- range .}}
+<tr>
+<td>{{.Name}}</td>
+<td>{{range $k, $v := .Labels}}{{$k}}={{$v}} {{end}}</td>
+<td>{{.Capacity}}</td>
+<td>{{.Len}} ({{printf "%.0f" (percent .Fill)}}%)</td>
+<td>{{.Dropped}}</td>
+<td>{{printf "%.2f" .DropRate}}/s</td>
+<td>{{.Collisions}}</td>
+<td>{{.ReaderIdle}}</td>
+</tr>
+{{- end}}
+</table>
+</body>
+</html>
+`))
@@ -0,0 +1,101 @@
+package registry_test
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+
+	"code.cloudfoundry.org/go-diodes"
+	"code.cloudfoundry.org/go-diodes/registry"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("ServeHTTP", func() {
+	var (
+		r      *registry.Registry
+		server *httptest.Server
+	)
+
+	BeforeEach(func() {
+		r = registry.New()
+		server = httptest.NewServer(r)
+
+		full := diodes.NewOneToOne(2, nil)
+		data := 1
+		full.Set(diodes.GenericDataType(&data))
+		full.Set(diodes.GenericDataType(&data))
+
+		Expect(r.Register("empty", diodes.NewOneToOne(2, nil), nil)).To(Succeed())
+		Expect(r.Register("full<script>", full, registry.Labels{"app": "x"})).To(Succeed())
+	})
+
+	AfterEach(func() {
+		server.Close()
+	})
+
+	get := func(path string, header http.Header) (*http.Response, string) {
+		req, err := http.NewRequest(http.MethodGet, server.URL+path, nil)
+		Expect(err).ToNot(HaveOccurred())
+		for k, v := range header {
+			req.Header[k] = v
+		}
+
+		resp, err := http.DefaultClient.Do(req)
+		Expect(err).ToNot(HaveOccurred())
+		defer resp.Body.Close()
+
+		body, err := io.ReadAll(resp.Body)
+		Expect(err).ToNot(HaveOccurred())
+		return resp, string(body)
+	}
+
+	decode := func(body string) []registry.Entry {
+		var entries []registry.Entry
+		Expect(json.Unmarshal([]byte(body), &entries)).To(Succeed())
+		return entries
+	}
+
+	It("serves an HTML page by default", func() {
+		resp, body := get("/", nil)
+		Expect(resp.Header.Get("Content-Type")).To(HavePrefix("text/html"))
+		Expect(body).To(ContainSubstring("<td>empty</td>"))
+		Expect(body).To(ContainSubstring("full&lt;script&gt;"))
+		Expect(body).To(ContainSubstring("app=x"))
+		Expect(body).To(ContainSubstring("2 (100%)"))
+	})
+
+	It("serves JSON when asked for", func() {
+		resp, body := get("/?format=json", nil)
+		Expect(resp.Header.Get("Content-Type")).To(Equal("application/json"))
+		Expect(decode(body)).To(HaveLen(2))
+
+		_, body = get("/", http.Header{"Accept": {"application/json"}})
+		Expect(decode(body)).To(HaveLen(2))
+	})
+
+	It("sorts by name by default", func() {
+		_, body := get("/?format=json", nil)
+		entries := decode(body)
+		Expect(entries[0].Name).To(Equal("empty"))
+	})
+
+	It("sorts numeric columns in descending order", func() {
+		_, body := get("/?format=json&sort=fill", nil)
+		entries := decode(body)
+		Expect(entries[0].Name).To(Equal("full<script>"))
+		Expect(entries[0].Fill).To(Equal(1.0))
+	})
+
+	It("accepts sorting by drop rate", func() {
+		resp, _ := get("/?sort=drop_rate", nil)
+		Expect(resp.StatusCode).To(Equal(http.StatusOK))
+	})
+
+	It("rejects unknown sort columns", func() {
+		resp, _ := get("/?sort=bogus", nil)
+		Expect(resp.StatusCode).To(Equal(http.StatusBadRequest))
+	})
+})
@@ -0,0 +1,178 @@
+// Package registry keeps track of named diodes so that the ones dropping
+// data can be found in processes with many diodes. The registry can be
+// served as an HTML or JSON debug page.
+package registry
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"code.cloudfoundry.org/go-diodes"
+)
+
+// rateInterval is the minimum time between two samples used to compute the
+// drop rate. Snapshots taken more often reuse the previous rate.
+const rateInterval = time.Second
+
+// Labels are arbitrary key value pairs that describe a diode.
+type Labels map[string]string
+
+// Entry describes a registered diode at the time of a snapshot.
+type Entry struct {
+	Name       string        `json:"name"`
+	Labels     Labels        `json:"labels,omitempty"`
+	Capacity   int           `json:"capacity"`
+	Len        int           `json:"len"`
+	Fill       float64       `json:"fill"`
+	Dropped    uint64        `json:"dropped"`
+	DropRate   float64       `json:"drop_rate"`
+	Collisions uint64        `json:"collisions"`
+	ReaderIdle time.Duration `json:"reader_idle_ns"`
+}
+
+// Registry holds named diodes. It is safe to use from several go-routines.
+type Registry struct {
+	mu      sync.Mutex
+	clock   diodes.Clock
+	entries map[string]*entry
+}
+
+type entry struct {
+	labels Labels
+	d      diodes.StatsReporter
+
+	sampledAt time.Time
+	dropped   uint64
+	dropRate  float64
+	reads     uint64
+	readAt    time.Time
+}
+
+// ConfigOption can be used to setup the registry.
+type ConfigOption func(*Registry)
+
+// WithClock sets the clock used to compute drop rates and reader idle times.
+// The default is the system clock.
+func WithClock(clock diodes.Clock) ConfigOption {
+	return ConfigOption(func(r *Registry) {
+		r.clock = clock
+	})
+}
+
+// New returns an empty Registry.
+func New(opts ...ConfigOption) *Registry {
+	r := &Registry{
+		clock:   systemClock{},
+		entries: make(map[string]*entry),
+	}
+
+	for _, o := range opts {
+		o(r)
+	}
+
+	return r
+}
+
+// Default is the registry used by the package level functions.
+var Default = New()
+
+// Register adds a diode to the Default registry.
+func Register(name string, d diodes.StatsReporter, labels Labels) error {
+	return Default.Register(name, d, labels)
+}
+
+// Unregister removes a diode from the Default registry.
+func Unregister(name string) {
+	Default.Unregister(name)
+}
+
+// Register adds a diode under the given name. It returns an error if the name
+// is already taken.
+func (r *Registry) Register(name string, d diodes.StatsReporter, labels Labels) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.entries[name]; ok {
+		return fmt.Errorf("diode %q is already registered", name)
+	}
+
+	now := r.clock.Now()
+	s := d.Stats()
+	r.entries[name] = &entry{
+		labels:    labels,
+		d:         d,
+		sampledAt: now,
+		dropped:   s.Dropped + s.Expired,
+		reads:     s.Reads,
+		readAt:    now,
+	}
+
+	return nil
+}
+
+// Unregister removes the diode with the given name.
+func (r *Registry) Unregister(name string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	delete(r.entries, name)
+}
+
+// Snapshot returns an Entry for each registered diode ordered by name. The
+// drop rate and reader idle time are measured between snapshots: the drop
+// rate is the number of values dropped (or expired) per second and the
+// reader idle time is how long it has been since a snapshot saw the reader
+// make progress.
+func (r *Registry) Snapshot() []Entry {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	now := r.clock.Now()
+	entries := make([]Entry, 0, len(r.entries))
+	for name, e := range r.entries {
+		s := e.d.Stats()
+		dropped := s.Dropped + s.Expired
+
+		if elapsed := now.Sub(e.sampledAt); elapsed >= rateInterval {
+			e.dropRate = float64(dropped-e.dropped) / elapsed.Seconds()
+			e.dropped = dropped
+			e.sampledAt = now
+		}
+
+		if s.Reads != e.reads {
+			e.reads = s.Reads
+			e.readAt = now
+		}
+
+		var fill float64
+		if s.Capacity > 0 {
+			fill = float64(s.Len) / float64(s.Capacity)
+		}
+
+		entries = append(entries, Entry{
+			Name:       name,
+			Labels:     e.labels,
+			Capacity:   s.Capacity,
+			Len:        s.Len,
+			Fill:       fill,
+			Dropped:    dropped,
+			DropRate:   e.dropRate,
+			Collisions: s.Collisions,
+			ReaderIdle: now.Sub(e.readAt),
+		})
+	}
+
+	sort.Slice(entries, func(i, j int) bool {
+		return entries[i].Name < entries[j].Name
+	})
+
+	return entries
+}
+
+type systemClock struct{}
+
+func (systemClock) Now() time.Time {
+	return time.Now()
+}
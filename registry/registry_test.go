@@ -0,0 +1,117 @@
+package registry_test
+
+import (
+	"sync"
+	"time"
+
+	"code.cloudfoundry.org/go-diodes"
+	"code.cloudfoundry.org/go-diodes/registry"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Registry", func() {
+	var (
+		r     *registry.Registry
+		clock *fakeClock
+		d     *diodes.ManyToOne
+	)
+
+	BeforeEach(func() {
+		clock = &fakeClock{now: time.Unix(1000, 0)}
+		r = registry.New(registry.WithClock(clock))
+		d = diodes.NewManyToOne(4, nil)
+	})
+
+	set := func(n int) {
+		data := 1
+		for i := 0; i < n; i++ {
+			d.Set(diodes.GenericDataType(&data))
+		}
+	}
+
+	It("lists registered diodes by name", func() {
+		Expect(r.Register("b", d, nil)).To(Succeed())
+		Expect(r.Register("a", diodes.NewOneToOne(8, nil), registry.Labels{"source": "app"})).To(Succeed())
+
+		entries := r.Snapshot()
+		Expect(entries).To(HaveLen(2))
+		Expect(entries[0].Name).To(Equal("a"))
+		Expect(entries[0].Labels).To(Equal(registry.Labels{"source": "app"}))
+		Expect(entries[0].Capacity).To(Equal(8))
+		Expect(entries[1].Name).To(Equal("b"))
+	})
+
+	It("rejects duplicate names", func() {
+		Expect(r.Register("a", d, nil)).To(Succeed())
+		Expect(r.Register("a", d, nil)).ToNot(Succeed())
+	})
+
+	It("unregisters diodes", func() {
+		Expect(r.Register("a", d, nil)).To(Succeed())
+		r.Unregister("a")
+
+		Expect(r.Snapshot()).To(BeEmpty())
+	})
+
+	It("reports the fill level", func() {
+		Expect(r.Register("a", d, nil)).To(Succeed())
+		set(2)
+
+		entries := r.Snapshot()
+		Expect(entries[0].Len).To(Equal(2))
+		Expect(entries[0].Fill).To(Equal(0.5))
+	})
+
+	It("reports the drop rate between snapshots", func() {
+		Expect(r.Register("a", d, nil)).To(Succeed())
+		set(14)
+		d.TryNext()
+
+		Expect(r.Snapshot()[0].DropRate).To(BeZero())
+
+		clock.Advance(2 * time.Second)
+		entries := r.Snapshot()
+		Expect(entries[0].Dropped).To(Equal(uint64(12)))
+		Expect(entries[0].DropRate).To(Equal(6.0))
+	})
+
+	It("reports how long the reader has been idle", func() {
+		Expect(r.Register("a", d, nil)).To(Succeed())
+		clock.Advance(time.Minute)
+		Expect(r.Snapshot()[0].ReaderIdle).To(Equal(time.Minute))
+
+		set(1)
+		d.TryNext()
+		Expect(r.Snapshot()[0].ReaderIdle).To(BeZero())
+
+		clock.Advance(time.Second)
+		Expect(r.Snapshot()[0].ReaderIdle).To(Equal(time.Second))
+	})
+
+	It("has a package level registry", func() {
+		Expect(registry.Register("registry-test", d, nil)).To(Succeed())
+		defer registry.Unregister("registry-test")
+
+		Expect(registry.Default.Snapshot()).To(ContainElement(HaveField("Name", "registry-test")))
+		Expect(registry.Handler()).To(Equal(registry.Default))
+	})
+})
+
+type fakeClock struct {
+	mu  sync.Mutex
+	now time.Time
+}
+
+func (c *fakeClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+func (c *fakeClock) Advance(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.now = c.now.Add(d)
+}
@@ -0,0 +1,201 @@
+package diodes
+
+import (
+	"sync"
+	"sync/atomic"
+)
+
+// Resizable diode is a ManyToOne diode whose size can be changed while
+// writers keep writing. A resize starts a new ring buffer for writes. The
+// reader first drains what is left in the old ring and then moves on to the
+// new one, so values are read in the order they were written. Unread values
+// that do not fit in the new size are dropped, oldest first. It is not
+// thread safe for multiple readers.
+type Resizable struct {
+	alerter Alerter
+	opts    []DiodeConfigOption
+
+	// current is the ring writers write to.
+	current atomic.Pointer[resizableRing]
+
+	// mu serializes resizes and guards head and retired.
+	mu sync.Mutex
+
+	// head is the ring the reader reads from. The rings from head to
+	// current are linked by next.
+	head    *resizableRing
+	retired Stats
+
+	// pending holds the values the reader moved out of a retired ring. It
+	// is only touched by the reader.
+	pending    []GenericDataType
+	pendingLen atomic.Int64
+}
+
+// resizableRing is one generation of a Resizable diode.
+type resizableRing struct {
+	d       *ManyToOne
+	writers atomic.Int64
+	next    atomic.Pointer[resizableRing]
+}
+
+// NewResizable creates a new Resizable diode of the given size. The alerter
+// is invoked on the read's go-routine when it notices that data was dropped,
+// including values that did not fit after a resize. A nil can be used to
+// ignore alerts. The options are passed to every ring.
+func NewResizable(size int, alerter Alerter, opts ...DiodeConfigOption) *Resizable {
+	if alerter == nil {
+		alerter = AlertFunc(func(int) {})
+	}
+
+	d := &Resizable{
+		alerter: alerter,
+		opts:    opts,
+	}
+	r := d.newRing(size)
+	d.current.Store(r)
+	d.head = r
+
+	return d
+}
+
+// Set sets the data in the next slot of the current ring buffer.
+func (d *Resizable) Set(data GenericDataType) {
+	for {
+		r := d.current.Load()
+		r.writers.Add(1)
+
+		// A resize might have started a new ring after it was loaded. The
+		// reader must be able to tell when the old ring gets no more
+		// writes, so write to the new ring instead.
+		if d.current.Load() != r {
+			r.writers.Add(-1)
+			continue
+		}
+
+		r.d.Set(data)
+		r.writers.Add(-1)
+		return
+	}
+}
+
+// TryNext will attempt to read the next value. If there is no data
+// available, it will return (nil, false).
+func (d *Resizable) TryNext() (data GenericDataType, ok bool) {
+	for {
+		if len(d.pending) > 0 {
+			data = d.pending[0]
+			d.pending[0] = nil
+			d.pending = d.pending[1:]
+			d.pendingLen.Add(-1)
+			return data, true
+		}
+
+		r := d.head
+		next := r.next.Load()
+		if next == nil || r.writers.Load() != 0 {
+			return r.d.TryNext()
+		}
+
+		// The ring was replaced and no writer is left, so it can be
+		// drained into pending and retired.
+		d.retire(r, next)
+	}
+}
+
+// Resize starts a new ring buffer of the given size, rounded up to the next
+// power of two, for writes. It does not block on the reader or the writers
+// and is safe to call from any go-routine.
+func (d *Resizable) Resize(size int) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	cur := d.current.Load()
+	if cur.d.Cap() == ringSize(size) {
+		return
+	}
+
+	// The new ring must be published to writers before it is linked for the
+	// reader. Once the reader sees next, it retires the old ring as soon as
+	// no writer is left. A writer that loaded the old ring increments its
+	// writers before it checks current again, so it either sees the new ring
+	// or is still counted when the reader checks.
+	r := d.newRing(size)
+	d.current.Store(r)
+	cur.next.Store(r)
+}
+
+// Cap returns the size of the current ring buffer.
+func (d *Resizable) Cap() int {
+	return d.current.Load().d.Cap()
+}
+
+// Len returns the approximate number of values that were not read yet. It is
+// safe to call from any go-routine.
+func (d *Resizable) Len() int {
+	return d.Stats().Len
+}
+
+// Stats returns the Stats of all rings since the diode was created. The
+// Capacity is the size of the current ring. It is safe to call from any
+// go-routine.
+func (d *Resizable) Stats() Stats {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	s := d.retired
+	for r := d.head; r != nil; r = r.next.Load() {
+		addStats(&s, r.d.Stats())
+	}
+	s.Capacity = d.current.Load().d.Cap()
+	s.Len += int(d.pendingLen.Load())
+
+	return s
+}
+
+func (d *Resizable) newRing(size int) *resizableRing {
+	return &resizableRing{
+		d: NewManyToOne(size, d.alerter, d.opts...),
+	}
+}
+
+// retire moves the values left in the ring to pending and makes the next
+// ring the one the reader reads from. Values that do not fit in the next
+// ring are dropped. It is invoked by the reader.
+func (d *Resizable) retire(r, next *resizableRing) {
+	for {
+		data, ok := r.d.TryNext()
+		if !ok {
+			break
+		}
+		d.pending = append(d.pending, data)
+	}
+
+	// Values the reader could not get to, for example behind a slot a
+	// colliding writer gave up on, are reported as dropped.
+	r.d.Reset()
+
+	var surplus int
+	if surplus = len(d.pending) - next.d.Cap(); surplus > 0 {
+		clear(d.pending[:surplus])
+		d.pending = d.pending[surplus:]
+	}
+	d.pendingLen.Store(int64(len(d.pending)))
+
+	d.mu.Lock()
+	addStats(&d.retired, r.d.Stats())
+	if surplus > 0 {
+		d.retired.Reads -= uint64(surplus)
+		d.retired.Dropped += uint64(surplus)
+	}
+	d.head = next
+	d.mu.Unlock()
+
+	if surplus > 0 {
+		if a, ok := d.alerter.(ReasonAlerter); ok {
+			a.AlertReason(surplus, DropOverwritten)
+		} else {
+			d.alerter.Alert(surplus)
+		}
+	}
+}
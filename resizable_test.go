@@ -0,0 +1,238 @@
+package diodes_test
+
+import (
+	"fmt"
+	"runtime"
+	"sync"
+
+	"code.cloudfoundry.org/go-diodes"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Resizable", func() {
+	var (
+		spy *spyAlerter
+		d   *diodes.Resizable
+	)
+
+	set := func(values ...int) {
+		for _, v := range values {
+			d.Set(diodes.GenericDataType(&v))
+		}
+	}
+
+	readAll := func() []int {
+		var read []int
+		for {
+			data, ok := d.TryNext()
+			if !ok {
+				return read
+			}
+			read = append(read, *(*int)(data))
+		}
+	}
+
+	BeforeEach(func() {
+		spy = newSpyAlerter()
+		d = diodes.NewResizable(4, spy)
+	})
+
+	It("reads what was written", func() {
+		set(1, 2, 3)
+		Expect(readAll()).To(Equal([]int{1, 2, 3}))
+		Expect(d.Cap()).To(Equal(4))
+	})
+
+	It("keeps the order across a resize", func() {
+		set(1, 2)
+		d.Resize(8)
+		set(3, 4)
+
+		Expect(d.Cap()).To(Equal(8))
+		Expect(d.Len()).To(Equal(4))
+		Expect(readAll()).To(Equal([]int{1, 2, 3, 4}))
+		Expect(spy.AlertCalled).ToNot(Receive())
+	})
+
+	It("keeps the order across several resizes", func() {
+		set(1)
+		d.Resize(2)
+		set(2)
+		d.Resize(8)
+		set(3)
+
+		Expect(readAll()).To(Equal([]int{1, 2, 3}))
+	})
+
+	It("drops the oldest values that do not fit", func() {
+		set(1, 2, 3, 4)
+		d.Resize(2)
+		set(5)
+
+		Expect(readAll()).To(Equal([]int{3, 4, 5}))
+		Expect(spy.AlertInput.Missed).To(Receive(Equal(2)))
+
+		s := d.Stats()
+		Expect(s.Writes).To(Equal(uint64(5)))
+		Expect(s.Reads).To(Equal(uint64(3)))
+		Expect(s.Dropped).To(Equal(uint64(2)))
+		Expect(s.Capacity).To(Equal(2))
+	})
+
+	It("does not lose values written while it is resized", func() {
+		const (
+			writers = 4
+			writes  = 2000
+		)
+
+		type value struct {
+			writer int
+			n      int
+		}
+
+		// The rings are larger than everything that is written, so every
+		// value has to be read exactly once and in order, no matter which
+		// ring it was written to.
+		d = diodes.NewResizable(8192, nil)
+
+		var wg sync.WaitGroup
+		for i := 0; i < writers; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				for n := 1; n <= writes; n++ {
+					d.Set(diodes.GenericDataType(&value{writer: i, n: n}))
+				}
+			}()
+		}
+
+		done := make(chan struct{})
+		go func() {
+			defer close(done)
+			for i := 0; i < 200; i++ {
+				d.Resize(8192 << (i % 2))
+				runtime.Gosched()
+			}
+			wg.Wait()
+		}()
+
+		var (
+			last  [writers]int
+			gaps  []string
+			reads int
+		)
+		read := func() bool {
+			data, ok := d.TryNext()
+			if !ok {
+				return false
+			}
+
+			v := (*value)(data)
+			if v.n != last[v.writer]+1 {
+				gaps = append(gaps, fmt.Sprintf("writer %d: read %d after %d", v.writer, v.n, last[v.writer]))
+			}
+			last[v.writer] = v.n
+			reads++
+			return true
+		}
+
+	loop:
+		for {
+			select {
+			case <-done:
+				break loop
+			default:
+				if !read() {
+					runtime.Gosched()
+				}
+			}
+		}
+		for read() {
+		}
+
+		Expect(gaps).To(BeEmpty())
+		Expect(reads).To(Equal(writers * writes))
+		Expect(d.Stats().Dropped).To(BeZero())
+	})
+
+	It("ignores a resize to the same size", func() {
+		set(1)
+		d.Resize(4)
+		set(2)
+
+		Expect(readAll()).To(Equal([]int{1, 2}))
+	})
+
+	It("keeps the values of each writer in order while resizing", func() {
+		const (
+			writers = 4
+			writes  = 5000
+		)
+
+		type value struct {
+			writer int
+			n      int
+		}
+
+		d = diodes.NewResizable(64, nil)
+
+		var wg sync.WaitGroup
+		for i := 0; i < writers; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				for n := 1; n <= writes; n++ {
+					d.Set(diodes.GenericDataType(&value{writer: i, n: n}))
+				}
+			}()
+		}
+
+		done := make(chan struct{})
+		go func() {
+			defer close(done)
+			for i := 0; i < 100; i++ {
+				d.Resize(16 << (i % 4))
+			}
+			wg.Wait()
+		}()
+
+		var (
+			last     [writers]int
+			reads    uint64
+			reorders []string
+		)
+		read := func() bool {
+			data, ok := d.TryNext()
+			if !ok {
+				return false
+			}
+
+			v := (*value)(data)
+			if v.n <= last[v.writer] {
+				reorders = append(reorders, fmt.Sprintf("writer %d: read %d after %d", v.writer, v.n, last[v.writer]))
+			}
+			last[v.writer] = v.n
+			reads++
+			return true
+		}
+
+	loop:
+		for {
+			select {
+			case <-done:
+				break loop
+			default:
+				read()
+			}
+		}
+		for read() {
+		}
+
+		Expect(reorders).To(BeEmpty())
+		s := d.Stats()
+		Expect(s.Writes).To(Equal(uint64(writers * writes)))
+		Expect(s.Reads).To(Equal(reads))
+	})
+})
@@ -0,0 +1,324 @@
+package diodes
+
+import (
+	"math/bits"
+	"sync/atomic"
+	"time"
+	"unsafe"
+)
+
+// ring holds the buffer and the reader's state shared by the OneToOne and
+// ManyToOne diodes.
+type ring struct {
+	buffer  []unsafe.Pointer
+	mask    uint64
+	alerter Alerter
+	config  diodeConfig
+	latency *histogram
+	tags    *tagCounts
+
+	dropped     atomic.Uint64
+	expired     atomic.Uint64
+	overwritten atomic.Uint64
+	resets      atomic.Uint64
+
+	// lossy is the list of producers with lost values the reader has not
+	// reported yet.
+	lossy atomic.Pointer[Producer]
+
+	// readIndex is stored by the reader on every read. It is kept on its
+	// own cache line so that it does not slow down writers loading the
+	// fields above.
+	_         [cacheLineSize]byte
+	readIndex atomic.Uint64
+	_         [cacheLineSize - 8]byte
+}
+
+// cacheLineSize is the size of a cache line on common CPUs.
+const cacheLineSize = 64
+
+// init sets up the ring. It must be called before the ring is used.
+func (r *ring) init(size int, alerter Alerter, opts []DiodeConfigOption) {
+	if alerter == nil {
+		alerter = AlertFunc(func(int) {})
+	}
+
+	r.buffer = make([]unsafe.Pointer, ringSize(size))
+	r.mask = uint64(len(r.buffer) - 1)
+	r.alerter = alerter
+	r.config = newDiodeConfig(opts)
+
+	if r.config.latency {
+		r.latency = new(histogram)
+	}
+
+	if r.config.tagDrops {
+		r.tags = new(tagCounts)
+	}
+}
+
+// ringSize rounds the size up to the next power of two. With a power of two
+// the slot of an index can be found by masking, and the slots of consecutive
+// indexes stay consecutive when the indexes wrap around at math.MaxUint64.
+func ringSize(size int) int {
+	if size <= 1 {
+		return 1
+	}
+
+	return 1 << bits.Len(uint(size-1))
+}
+
+// seqDiff returns the distance from b to a. Comparing sequence numbers by
+// their distance instead of with < and > keeps working when the indexes wrap
+// around at math.MaxUint64.
+func seqDiff(a, b uint64) int64 {
+	return int64(a - b) // nolint:gosec
+}
+
+// newMeta returns the metadata to store with a value, or nil when neither the
+// options nor a Producer need any.
+func (r *ring) newMeta(tag Tag, p *Producer, pseq uint64) *bucketMeta {
+	ts := r.config.stamp()
+	if r.tags == nil {
+		tag = 0
+	}
+
+	if ts == 0 && tag == 0 && p == nil {
+		return nil
+	}
+
+	return &bucketMeta{
+		ts:       ts,
+		producer: p,
+		pseq:     pseq,
+		tag:      tag,
+	}
+}
+
+// slot returns the slot of the given index.
+func (r *ring) slot(index uint64) *unsafe.Pointer {
+	return &r.buffer[index&r.mask]
+}
+
+// next will attempt to read the next bucket from the ring buffer. Buckets
+// that have expired are skipped. The read index is stored once, before the
+// dropped and expired values are counted, so that stats never sees more of
+// them than the read index accounts for.
+func (r *ring) next() (*bucket, bool) {
+	var (
+		now       int64
+		dropped   uint64
+		expired   uint64
+		start     = r.readIndex.Load()
+		readIndex = start
+	)
+
+	for {
+		// Read a value from the ring buffer based on the readIndex.
+		result := (*bucket)(atomic.SwapPointer(r.slot(readIndex), nil))
+
+		// When the result is nil that means the writer has not had the
+		// opportunity to write a value into the diode. This value must be
+		// ignored and the read head must not increment.
+		if result == nil {
+			break
+		}
+
+		// When the seq value is less than the current read index that means
+		// a value was read from idx that was previously written but has since
+		// has been dropped. This value must be ignored and the read head must
+		// not increment.
+		//
+		// The simulation for this scenario assumes the fast forward occurred
+		// as detailed below.
+		//
+		// 5. The reader reads again getting seq 5. It then reads again
+		//    expecting seq 6 but gets seq 2. This is a read of a stale value
+		//    that was effectively "dropped" so the read fails and the read
+		//    head stays put.
+		//    `| 4 | 5 | 2 | 3 |` r: 7, w: 6
+		//
+		if seqDiff(result.seq, readIndex) < 0 {
+			r.lost(result)
+			break
+		}
+
+		// When the seq value is greater than the current read index that
+		// means a value was read from idx that overwrote the value that was
+		// expected to be at this idx. This happens when the writer has lapped
+		// the reader. The reader needs to catch up to the writer so it moves
+		// its write head to the new seq, effectively dropping the messages
+		// that were not read in between the two values.
+		//
+		// Here is a simulation of this scenario:
+		//
+		// 1. Both the read and write heads start at 0.
+		//    `| nil | nil | nil | nil |` r: 0, w: 0
+		// 2. The writer fills the buffer.
+		//    `| 0 | 1 | 2 | 3 |` r: 0, w: 4
+		// 3. The writer laps the read head.
+		//    `| 4 | 5 | 2 | 3 |` r: 0, w: 6
+		// 4. The reader reads the first value, expecting a seq of 0 but reads
+		//    4, this forces the reader to fast forward to 5.
+		//    `| 4 | 5 | 2 | 3 |` r: 5, w: 6
+		//
+		if seqDiff(result.seq, readIndex) > 0 {
+			dropped += result.seq - readIndex
+			readIndex = result.seq
+		}
+
+		// Only increment read index if a regular read occurred (where seq was
+		// equal to readIndex) or a value was read that caused a fast forward
+		// (where seq was greater than readIndex).
+		readIndex++
+
+		ts := result.ts()
+		if ts != 0 && now == 0 {
+			now = r.config.clock.Now().UnixNano()
+		}
+
+		// When a max age is configured, values that are too old are skipped
+		// and the next slot is read instead.
+		if r.config.maxAge > 0 && now-ts > int64(r.config.maxAge) {
+			r.lost(result)
+			expired++
+			continue
+		}
+
+		if r.latency != nil {
+			r.latency.record(time.Duration(now - ts))
+		}
+
+		r.advance(start, readIndex, dropped, expired)
+		return result, true
+	}
+
+	r.advance(start, readIndex, dropped, expired)
+	return nil, false
+}
+
+// advance stores the read index and reports what the reader dropped and
+// skipped since it was loaded.
+func (r *ring) advance(start, readIndex, dropped, expired uint64) {
+	if readIndex != start {
+		r.readIndex.Store(readIndex)
+	}
+
+	r.alert(dropped, DropOverwritten)
+	r.alert(expired, DropExpired)
+	r.alertTags()
+	r.alertProducers()
+}
+
+// overwrote is invoked by writers with the bucket they replaced. When writer
+// drop detection is enabled, a bucket the reader has not reached yet is
+// counted as overwritten. Buckets behind the read index were skipped by the
+// reader and already reported as dropped.
+func (r *ring) overwrote(old unsafe.Pointer) {
+	if old == nil {
+		return
+	}
+
+	r.lost((*bucket)(old))
+	if !r.config.writerDrops {
+		return
+	}
+
+	if seqDiff((*bucket)(old).seq, r.readIndex.Load()) >= 0 {
+		r.overwritten.Add(1)
+	}
+}
+
+// alert reports dropped values to the alerter.
+func (r *ring) alert(missed uint64, reason DropReason) {
+	if missed == 0 {
+		return
+	}
+
+	switch reason {
+	case DropOverwritten:
+		r.dropped.Add(missed)
+	case DropReset:
+		r.dropped.Add(missed)
+		r.resets.Add(missed)
+	case DropExpired:
+		r.expired.Add(missed)
+	}
+
+	if a, ok := r.alerter.(ReasonAlerter); ok {
+		a.AlertReason(int(missed), reason) // nolint:gosec
+		return
+	}
+
+	r.alerter.Alert(int(missed)) // nolint:gosec
+}
+
+// len returns the approximate number of unread values given the number of
+// writes. It is at most the capacity of the ring.
+func (r *ring) len(writes uint64) int {
+	unread := writes - r.readIndex.Load()
+	switch {
+	case unread <= uint64(len(r.buffer)):
+		return int(unread) // nolint:gosec
+	case unread < 1<<63:
+		return len(r.buffer)
+	default:
+		// The reader is ahead of a write index that was loaded before it.
+		return 0
+	}
+}
+
+// reset moves the read index to the given number of writes and removes the
+// values before it from the ring. The values that were not read are
+// reported as dropped. Values of writers that are still writing at or after
+// the new read index are kept.
+func (r *ring) reset(writes uint64) {
+	readIndex := r.readIndex.Load()
+	if seqDiff(writes, readIndex) <= 0 {
+		return
+	}
+	r.readIndex.Store(writes)
+
+	for i := range r.buffer {
+		old := atomic.LoadPointer(&r.buffer[i])
+		if old == nil || seqDiff((*bucket)(old).seq, writes) >= 0 {
+			continue
+		}
+
+		if atomic.CompareAndSwapPointer(&r.buffer[i], old, nil) {
+			r.lost((*bucket)(old))
+		}
+	}
+
+	r.alert(writes-readIndex, DropReset)
+	r.alertTags()
+	r.alertProducers()
+}
+
+// stats returns the counters of the ring given the number of writes. Every
+// index the reader moved past was either read, dropped or expired, so the
+// reads are not counted separately. The reader stores the read index before
+// it counts the others, so they are loaded first.
+func (r *ring) stats(writes uint64) Stats {
+	s := Stats{
+		Capacity:    len(r.buffer),
+		Writes:      writes,
+		Dropped:     r.dropped.Load(),
+		Expired:     r.expired.Load(),
+		Overwritten: r.overwritten.Load(),
+		Reset:       r.resets.Load(),
+	}
+
+	s.Reads = r.readIndex.Load() - s.Dropped - s.Expired
+	s.Len = r.len(writes)
+
+	if r.latency != nil {
+		s.Latency = r.latency.snapshot()
+	}
+
+	if r.tags != nil {
+		s.TagDrops = r.tags.snapshot()
+	}
+
+	return s
+}
@@ -0,0 +1,128 @@
+package diodes
+
+import (
+	"math/rand/v2"
+)
+
+// ShardAlerter is an Alerter that is also told which shard of a
+// ShardedManyToOne dropped values. When the alerter given to a
+// ShardedManyToOne implements ShardAlerter, AlertShard is invoked instead of
+// Alert.
+type ShardAlerter interface {
+	Alerter
+	AlertShard(shard, missed int)
+}
+
+// ShardAlertFunc type is an adapter to allow the use of ordinary functions
+// as ShardAlerters.
+type ShardAlertFunc func(shard, missed int)
+
+// Alert calls f(0, missed)
+func (f ShardAlertFunc) Alert(missed int) {
+	f(0, missed)
+}
+
+// AlertShard calls f(shard, missed)
+func (f ShardAlertFunc) AlertShard(shard, missed int) {
+	f(shard, missed)
+}
+
+// ShardedManyToOne diode spreads its writers over several ManyToOne shards,
+// each with its own ring buffer and write index, so that writers rarely
+// contend on the same index. The single reader reads the shards round-robin
+// so that a busy shard can not starve the others. Values are only ordered
+// within a shard: values written with the same key are read in order, values
+// written with Set or different keys are not. It is not thread safe for
+// multiple readers.
+type ShardedManyToOne struct {
+	shards []*ManyToOne
+	next   int
+}
+
+// NewShardedManyToOne creates a new diode with the given number of shards,
+// each with a ring buffer of the given size. The alerter is invoked on the
+// read's go-routine when it notices that data in a shard was dropped. A nil
+// can be used to ignore alerts. The options are passed to every shard.
+func NewShardedManyToOne(shards, size int, alerter Alerter, opts ...DiodeConfigOption) *ShardedManyToOne {
+	if shards < 1 {
+		panic("diodes: a ShardedManyToOne requires at least one shard")
+	}
+
+	d := &ShardedManyToOne{
+		shards: make([]*ManyToOne, shards),
+	}
+	for i := range d.shards {
+		d.shards[i] = NewManyToOne(size, shardAlerter(i, alerter), opts...)
+	}
+
+	return d
+}
+
+// Shards returns the number of shards.
+func (d *ShardedManyToOne) Shards() int {
+	return len(d.shards)
+}
+
+// Set sets the data in a randomly picked shard. Picking a shard does not
+// touch any state shared with other writers.
+func (d *ShardedManyToOne) Set(data GenericDataType) {
+	d.shards[rand.IntN(len(d.shards))].Set(data) // nolint:gosec
+}
+
+// SetKey sets the data in the shard picked by the key, for example a hash
+// of the producer's name. Values written with the same key are read in the
+// order they were written.
+func (d *ShardedManyToOne) SetKey(key uint64, data GenericDataType) {
+	d.shards[key%uint64(len(d.shards))].Set(data)
+}
+
+// TryNext will attempt to read from the shard after the one that was read
+// last, moving on to the next shard until it finds data. If there is no
+// data available in any shard, it will return (nil, false).
+func (d *ShardedManyToOne) TryNext() (data GenericDataType, ok bool) {
+	for i := range d.shards {
+		shard := (d.next + i) % len(d.shards)
+		if data, ok := d.shards[shard].TryNext(); ok {
+			d.next = shard + 1
+			return data, true
+		}
+	}
+
+	return nil, false
+}
+
+// Stats returns the sum of the Stats of all shards. It is safe to call from
+// any go-routine.
+func (d *ShardedManyToOne) Stats() Stats {
+	var s Stats
+	for _, shard := range d.shards {
+		addStats(&s, shard.Stats())
+	}
+
+	return s
+}
+
+// ShardStats returns the Stats of each shard. It is safe to call from any
+// go-routine.
+func (d *ShardedManyToOne) ShardStats() []Stats {
+	stats := make([]Stats, len(d.shards))
+	for i, shard := range d.shards {
+		stats[i] = shard.Stats()
+	}
+
+	return stats
+}
+
+// shardAlerter returns the alerter of a single shard. It forwards to
+// AlertShard when the alerter is a ShardAlerter, and to the alerter itself
+// otherwise.
+func shardAlerter(shard int, alerter Alerter) Alerter {
+	sa, ok := alerter.(ShardAlerter)
+	if !ok {
+		return alerter
+	}
+
+	return AlertFunc(func(missed int) {
+		sa.AlertShard(shard, missed)
+	})
+}
@@ -0,0 +1,166 @@
+package diodes_test
+
+import (
+	"sync"
+	"time"
+
+	"code.cloudfoundry.org/go-diodes"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("ShardedManyToOne", func() {
+	It("panics without shards", func() {
+		Expect(func() { diodes.NewShardedManyToOne(0, 4, nil) }).To(Panic())
+	})
+
+	It("reads what was written", func() {
+		d := diodes.NewShardedManyToOne(4, 8, nil)
+		Expect(d.Shards()).To(Equal(4))
+
+		var read []int
+		for i := 0; i < 10; i++ {
+			data := i
+			d.Set(diodes.GenericDataType(&data))
+		}
+		for {
+			data, ok := d.TryNext()
+			if !ok {
+				break
+			}
+			read = append(read, *(*int)(data))
+		}
+
+		Expect(read).To(ConsistOf(0, 1, 2, 3, 4, 5, 6, 7, 8, 9))
+	})
+
+	It("reads values written with the same key in order", func() {
+		d := diodes.NewShardedManyToOne(4, 8, nil)
+		for i := 0; i < 5; i++ {
+			data := i
+			d.SetKey(3, diodes.GenericDataType(&data))
+		}
+
+		for i := 0; i < 5; i++ {
+			data, ok := d.TryNext()
+			Expect(ok).To(BeTrue())
+			Expect(*(*int)(data)).To(Equal(i))
+		}
+	})
+
+	It("reads the shards round-robin", func() {
+		d := diodes.NewShardedManyToOne(2, 8, nil)
+		for i := 0; i < 3; i++ {
+			a, b := i, 10+i
+			d.SetKey(0, diodes.GenericDataType(&a))
+			d.SetKey(1, diodes.GenericDataType(&b))
+		}
+
+		var read []int
+		for i := 0; i < 6; i++ {
+			data, ok := d.TryNext()
+			Expect(ok).To(BeTrue())
+			read = append(read, *(*int)(data))
+		}
+
+		Expect(read).To(Equal([]int{0, 10, 1, 11, 2, 12}))
+	})
+
+	It("reports drops per shard", func() {
+		var alerts [][2]int
+		d := diodes.NewShardedManyToOne(2, 2, diodes.ShardAlertFunc(func(shard, missed int) {
+			alerts = append(alerts, [2]int{shard, missed})
+		}))
+
+		data := 1
+		for i := 0; i < 5; i++ {
+			d.SetKey(1, diodes.GenericDataType(&data))
+		}
+		d.SetKey(0, diodes.GenericDataType(&data))
+		for {
+			if _, ok := d.TryNext(); !ok {
+				break
+			}
+		}
+
+		Expect(alerts).To(Equal([][2]int{{1, 4}}))
+		Expect(d.ShardStats()[0].Dropped).To(BeZero())
+		Expect(d.ShardStats()[1].Dropped).To(Equal(uint64(4)))
+	})
+
+	It("forwards to a plain Alerter", func() {
+		spy := newSpyAlerter()
+		d := diodes.NewShardedManyToOne(1, 2, spy)
+
+		data := 1
+		for i := 0; i < 5; i++ {
+			d.Set(diodes.GenericDataType(&data))
+		}
+		d.TryNext()
+
+		Expect(spy.AlertInput.Missed).To(Receive(Equal(4)))
+	})
+
+	It("sums the Stats of the shards", func() {
+		clock := newFakeClock()
+		d := diodes.NewShardedManyToOne(2, 4, nil, diodes.WithLatencyHistogram(), diodes.WithClock(clock))
+		data := 1
+		d.SetKey(0, diodes.GenericDataType(&data))
+		d.SetKey(1, diodes.GenericDataType(&data))
+		d.SetKey(1, diodes.GenericDataType(&data))
+		clock.Advance(time.Millisecond)
+		d.TryNext()
+		d.TryNext()
+
+		s := d.Stats()
+		Expect(s.Capacity).To(Equal(8))
+		Expect(s.Writes).To(Equal(uint64(3)))
+		Expect(s.Reads).To(Equal(uint64(2)))
+		Expect(s.Len).To(Equal(1))
+		Expect(s.Latency.Count).To(Equal(uint64(2)))
+		Expect(s.Latency.Buckets).To(HaveLen(1))
+		Expect(s.Latency.Buckets[0].Count).To(Equal(uint64(2)))
+	})
+
+	It("sums the tag drops of the shards", func() {
+		d := diodes.NewShardedManyToOne(2, 2, nil, diodes.WithTagDrops())
+		data := 1
+		for i := 0; i < 3; i++ {
+			d.SetKey(0, diodes.GenericDataType(&data))
+		}
+		for i := 0; i < 4; i++ {
+			d.SetKey(1, diodes.GenericDataType(&data))
+		}
+
+		Expect(d.ShardStats()[1].TagDrops).To(Equal(map[diodes.Tag]uint64{0: 2}))
+		Expect(d.Stats().TagDrops).To(Equal(map[diodes.Tag]uint64{0: 3}))
+		Expect(d.ShardStats()[1].TagDrops).To(Equal(map[diodes.Tag]uint64{0: 2}))
+	})
+
+	It("is safe for many writers", func() {
+		d := diodes.NewShardedManyToOne(4, 1000, nil)
+
+		var wg sync.WaitGroup
+		for i := 0; i < 4; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				data := 1
+				for j := 0; j < 100; j++ {
+					d.Set(diodes.GenericDataType(&data))
+				}
+			}()
+		}
+		wg.Wait()
+
+		var count int
+		for {
+			if _, ok := d.TryNext(); !ok {
+				break
+			}
+			count++
+		}
+		Expect(count).To(Equal(400))
+	})
+})
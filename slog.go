@@ -0,0 +1,128 @@
+package diodes
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+)
+
+// SlogHandler is a slog.Handler that enqueues records into a ManyToOne diode
+// and forwards them to a wrapped handler on a background go-routine, so
+// logging never blocks the caller. When records are dropped, a synthetic
+// "dropped N log records" warning is forwarded to the wrapped handler.
+type SlogHandler struct {
+	handler slog.Handler
+	q       *slogQueue
+}
+
+// slogQueue is shared by a SlogHandler and the handlers derived from it with
+// WithAttrs and WithGroup.
+type slogQueue struct {
+	handler slog.Handler
+	w       *Waiter
+	cancel  context.CancelFunc
+	done    chan struct{}
+}
+
+// slogEntry is a record together with the (derived) handler that has to
+// handle it.
+type slogEntry struct {
+	ctx     context.Context
+	handler slog.Handler
+	record  slog.Record
+}
+
+// NewSlogHandler returns a SlogHandler that forwards to the given handler
+// through a diode of the given size. It starts a go-routine that runs until
+// Close is invoked. The options are passed to the ManyToOne diode.
+func NewSlogHandler(h slog.Handler, size int, opts ...DiodeConfigOption) *SlogHandler {
+	ctx, cancel := context.WithCancel(context.Background())
+	q := &slogQueue{
+		handler: h,
+		cancel:  cancel,
+		done:    make(chan struct{}),
+	}
+	q.w = NewWaiter(NewManyToOne(size, AlertFunc(q.dropped), opts...), WithWaiterContext(ctx))
+
+	go q.run()
+
+	return &SlogHandler{
+		handler: h,
+		q:       q,
+	}
+}
+
+// Enabled reports whether the wrapped handler handles records at the given
+// level.
+func (h *SlogHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	return h.handler.Enabled(ctx, level)
+}
+
+// Handle enqueues a copy of the record. It never blocks and always returns
+// nil. Errors from the wrapped handler are ignored.
+func (h *SlogHandler) Handle(ctx context.Context, r slog.Record) error {
+	h.q.w.Set(GenericDataType(&slogEntry{
+		ctx:     ctx,
+		handler: h.handler,
+		record:  r.Clone(),
+	}))
+
+	return nil
+}
+
+// WithAttrs returns a SlogHandler that shares the diode and go-routine of h
+// and forwards to the wrapped handler with the given attributes.
+func (h *SlogHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return &SlogHandler{
+		handler: h.handler.WithAttrs(attrs),
+		q:       h.q,
+	}
+}
+
+// WithGroup returns a SlogHandler that shares the diode and go-routine of h
+// and forwards to the wrapped handler with the given group.
+func (h *SlogHandler) WithGroup(name string) slog.Handler {
+	return &SlogHandler{
+		handler: h.handler.WithGroup(name),
+		q:       h.q,
+	}
+}
+
+// Close forwards the records that are still queued and stops the background
+// go-routine. Records handled after Close are dropped silently. Closing any
+// handler derived from the same NewSlogHandler closes all of them.
+func (h *SlogHandler) Close() {
+	h.q.cancel()
+	<-h.q.done
+}
+
+// Stats returns the Stats of the diode.
+func (h *SlogHandler) Stats() Stats {
+	return h.q.w.Stats()
+}
+
+func (q *slogQueue) run() {
+	defer close(q.done)
+
+	for {
+		data := q.w.Next()
+		if data == nil {
+			return
+		}
+
+		e := (*slogEntry)(data)
+		e.handler.Handle(e.ctx, e.record) //nolint:errcheck
+	}
+}
+
+// dropped is the diode's alerter. It runs on the background go-routine.
+func (q *slogQueue) dropped(missed int) {
+	r := slog.NewRecord(time.Now(), slog.LevelWarn, fmt.Sprintf("dropped %d log records", missed), 0)
+	r.AddAttrs(slog.Int("dropped", missed))
+
+	ctx := context.Background()
+	if q.handler.Enabled(ctx, r.Level) {
+		q.handler.Handle(ctx, r) //nolint:errcheck
+	}
+}
@@ -0,0 +1,185 @@
+package diodes_test
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+
+	"code.cloudfoundry.org/go-diodes"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("SlogHandler", func() {
+	var (
+		rec *recordingHandler
+		h   *diodes.SlogHandler
+	)
+
+	BeforeEach(func() {
+		rec = newRecordingHandler(slog.LevelInfo)
+		h = diodes.NewSlogHandler(rec, 16)
+	})
+
+	AfterEach(func() {
+		h.Close()
+	})
+
+	It("forwards records to the wrapped handler", func() {
+		slog.New(h).Info("hello", "key", "value")
+
+		Eventually(rec.Messages).Should(Equal([]string{"hello"}))
+		Expect(rec.Records()[0].attrs).To(Equal(map[string]string{"key": "value"}))
+	})
+
+	It("uses the wrapped handler to decide which levels are enabled", func() {
+		Expect(h.Enabled(context.Background(), slog.LevelDebug)).To(BeFalse())
+		Expect(h.Enabled(context.Background(), slog.LevelInfo)).To(BeTrue())
+	})
+
+	It("forwards attributes and groups of derived handlers", func() {
+		logger := slog.New(h).With("app", "router").WithGroup("req")
+		logger.Info("served", "status", 200)
+
+		Eventually(rec.Messages).Should(Equal([]string{"served"}))
+		Expect(rec.Records()[0].attrs).To(Equal(map[string]string{
+			"app":        "router",
+			"req.status": "200",
+		}))
+	})
+
+	It("forwards the queued records when it is closed", func() {
+		logger := slog.New(h)
+		for i := 0; i < 10; i++ {
+			logger.Info("message")
+		}
+		h.Close()
+
+		Expect(rec.Messages()).To(HaveLen(10))
+	})
+
+	It("reports dropped records with a synthetic record", func() {
+		rec.Block()
+		logger := slog.New(h)
+		logger.Info("first")
+		Eventually(rec.Blocked).Should(BeTrue())
+
+		for i := 0; i < 20; i++ {
+			logger.Info("message")
+		}
+		rec.Unblock()
+
+		Eventually(rec.Messages).Should(ContainElement("dropped 16 log records"))
+		for _, r := range rec.Records() {
+			if r.msg == "dropped 16 log records" {
+				Expect(r.level).To(Equal(slog.LevelWarn))
+				Expect(r.attrs).To(HaveKeyWithValue("dropped", "16"))
+			}
+		}
+		Expect(h.Stats().Dropped).To(Equal(uint64(16)))
+	})
+})
+
+type recordedLog struct {
+	level slog.Level
+	msg   string
+	attrs map[string]string
+}
+
+// recordingHandler records the messages and attributes it handles. It can be
+// blocked to simulate a slow sink.
+type recordingHandler struct {
+	level  slog.Level
+	prefix string
+	attrs  []slog.Attr
+	state  *recordingState
+}
+
+type recordingState struct {
+	mu      sync.Mutex
+	records []recordedLog
+	block   chan struct{}
+	blocked bool
+}
+
+func newRecordingHandler(level slog.Level) *recordingHandler {
+	return &recordingHandler{
+		level: level,
+		state: &recordingState{},
+	}
+}
+
+func (h *recordingHandler) Enabled(_ context.Context, level slog.Level) bool {
+	return level >= h.level
+}
+
+func (h *recordingHandler) Handle(_ context.Context, r slog.Record) error {
+	h.state.mu.Lock()
+	block := h.state.block
+	h.state.blocked = block != nil
+	h.state.mu.Unlock()
+	if block != nil {
+		<-block
+	}
+
+	attrs := make(map[string]string)
+	for _, a := range h.attrs {
+		attrs[a.Key] = a.Value.String()
+	}
+	r.Attrs(func(a slog.Attr) bool {
+		attrs[h.prefix+a.Key] = a.Value.String()
+		return true
+	})
+
+	h.state.mu.Lock()
+	defer h.state.mu.Unlock()
+	h.state.blocked = false
+	h.state.records = append(h.state.records, recordedLog{level: r.Level, msg: r.Message, attrs: attrs})
+	return nil
+}
+
+func (h *recordingHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	c := *h
+	c.attrs = append(append([]slog.Attr{}, h.attrs...), attrs...)
+	return &c
+}
+
+func (h *recordingHandler) WithGroup(name string) slog.Handler {
+	c := *h
+	c.prefix = h.prefix + name + "."
+	return &c
+}
+
+func (h *recordingHandler) Records() []recordedLog {
+	h.state.mu.Lock()
+	defer h.state.mu.Unlock()
+	return append([]recordedLog{}, h.state.records...)
+}
+
+func (h *recordingHandler) Messages() []string {
+	var msgs []string
+	for _, r := range h.Records() {
+		msgs = append(msgs, r.msg)
+	}
+	return msgs
+}
+
+func (h *recordingHandler) Block() {
+	h.state.mu.Lock()
+	defer h.state.mu.Unlock()
+	h.state.block = make(chan struct{})
+}
+
+func (h *recordingHandler) Blocked() bool {
+	h.state.mu.Lock()
+	defer h.state.mu.Unlock()
+	return h.state.blocked
+}
+
+func (h *recordingHandler) Unblock() {
+	h.state.mu.Lock()
+	defer h.state.mu.Unlock()
+	close(h.state.block)
+	h.state.block = nil
+}
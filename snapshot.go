@@ -0,0 +1,71 @@
+package diodes
+
+import (
+	"sort"
+	"sync/atomic"
+)
+
+// SnapshotEntry is an unread value of a diode together with the sequence
+// number it was written with.
+type SnapshotEntry struct {
+	Data GenericDataType
+	Seq  uint64
+}
+
+// peek returns the bucket the next read would return without removing it
+// from the ring. It follows the same rules as next: it stops at an empty or
+// stale slot, fast forwards past overwritten values and skips expired ones.
+func (r *ring) peek() (*bucket, bool) {
+	var (
+		now       int64
+		readIndex = r.readIndex.Load()
+	)
+
+	for range r.buffer {
+		result := (*bucket)(atomic.LoadPointer(r.slot(readIndex)))
+		if result == nil || seqDiff(result.seq, readIndex) < 0 {
+			return nil, false
+		}
+		readIndex = result.seq + 1
+
+		ts := result.ts()
+		if ts != 0 && now == 0 {
+			now = r.config.clock.Now().UnixNano()
+		}
+
+		if r.config.maxAge > 0 && now-ts > int64(r.config.maxAge) {
+			continue
+		}
+
+		return result, true
+	}
+
+	return nil, false
+}
+
+// snapshot returns the unread values in the ring ordered by sequence number,
+// given the number of writes. It only loads the slots and never changes the
+// ring, so it is safe to call from any go-routine. Since writers and the
+// reader keep going while the slots are loaded, the result is best effort.
+func (r *ring) snapshot(writes uint64) []SnapshotEntry {
+	readIndex := r.readIndex.Load()
+
+	var entries []SnapshotEntry
+	for i := range r.buffer {
+		b := (*bucket)(atomic.LoadPointer(&r.buffer[i]))
+		if b == nil || seqDiff(b.seq, readIndex) < 0 || seqDiff(b.seq, writes) >= 0 {
+			continue
+		}
+
+		entries = append(entries, SnapshotEntry{
+			Data: b.data,
+			Seq:  b.seq,
+		})
+	}
+
+	sort.Slice(entries, func(i, j int) bool {
+		return seqDiff(entries[i].Seq, entries[j].Seq) < 0
+	})
+
+	return entries
+}
@@ -0,0 +1,159 @@
+package diodes_test
+
+import (
+	"sync"
+	"time"
+
+	"code.cloudfoundry.org/go-diodes"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+type peekDiode interface {
+	diodes.Diode
+	Peek() (diodes.GenericDataType, bool)
+	Snapshot() []diodes.SnapshotEntry
+}
+
+var _ = Describe("Peek and Snapshot", func() {
+	for _, storage := range storageTypes {
+		constructor := storage.constructor
+
+		Context(storage.name, func() {
+			var (
+				clock *fakeClock
+				spy   *spyAlerter
+				d     peekDiode
+			)
+
+			set := func(values ...int) {
+				for _, v := range values {
+					d.Set(diodes.GenericDataType(&v))
+				}
+			}
+
+			values := func(entries []diodes.SnapshotEntry) []int {
+				var vs []int
+				for _, e := range entries {
+					vs = append(vs, *(*int)(e.Data))
+				}
+				return vs
+			}
+
+			BeforeEach(func() {
+				clock = newFakeClock()
+				spy = newSpyAlerter()
+				d = constructor(4, spy, diodes.WithMaxAge(time.Minute), diodes.WithClock(clock)).(peekDiode)
+			})
+
+			Describe("Peek", func() {
+				It("returns false without data", func() {
+					_, ok := d.Peek()
+					Expect(ok).To(BeFalse())
+				})
+
+				It("returns the next value without consuming it", func() {
+					set(1, 2)
+
+					data, ok := d.Peek()
+					Expect(ok).To(BeTrue())
+					Expect(*(*int)(data)).To(Equal(1))
+
+					data, _ = d.Peek()
+					Expect(*(*int)(data)).To(Equal(1))
+
+					data, _ = d.TryNext()
+					Expect(*(*int)(data)).To(Equal(1))
+					data, _ = d.Peek()
+					Expect(*(*int)(data)).To(Equal(2))
+				})
+
+				It("returns what the next read returns after the reader was lapped", func() {
+					set(0, 1, 2, 3, 4, 5)
+
+					data, ok := d.Peek()
+					Expect(ok).To(BeTrue())
+					Expect(*(*int)(data)).To(Equal(4))
+					Expect(spy.AlertCalled).ToNot(Receive())
+
+					data, _ = d.TryNext()
+					Expect(*(*int)(data)).To(Equal(4))
+				})
+
+				It("skips expired values", func() {
+					set(1)
+					clock.Advance(2 * time.Minute)
+					set(2)
+
+					data, ok := d.Peek()
+					Expect(ok).To(BeTrue())
+					Expect(*(*int)(data)).To(Equal(2))
+				})
+
+				It("returns false when every value expired", func() {
+					set(1, 2)
+					clock.Advance(2 * time.Minute)
+
+					_, ok := d.Peek()
+					Expect(ok).To(BeFalse())
+				})
+			})
+
+			Describe("Snapshot", func() {
+				It("is empty without data", func() {
+					Expect(d.Snapshot()).To(BeEmpty())
+				})
+
+				It("returns the unread values in order", func() {
+					set(1, 2, 3)
+					d.TryNext()
+
+					entries := d.Snapshot()
+					Expect(values(entries)).To(Equal([]int{2, 3}))
+					Expect(entries[0].Seq).To(Equal(uint64(1)))
+					Expect(entries[1].Seq).To(Equal(uint64(2)))
+
+					data, _ := d.TryNext()
+					Expect(*(*int)(data)).To(Equal(2))
+				})
+
+				It("leaves out values the reader skipped", func() {
+					set(0, 1, 2, 3, 4, 5)
+					Expect(values(d.Snapshot())).To(Equal([]int{2, 3, 4, 5}))
+
+					d.TryNext()
+					Expect(values(d.Snapshot())).To(Equal([]int{5}))
+				})
+
+				It("is safe to call while reading and writing", func() {
+					d = constructor(4, nil).(peekDiode)
+
+					var wg sync.WaitGroup
+					wg.Add(2)
+					go func() {
+						defer wg.Done()
+						for i := 0; i < 1000; i++ {
+							set(i)
+						}
+					}()
+					go func() {
+						defer wg.Done()
+						for i := 0; i < 1000; i++ {
+							d.TryNext()
+						}
+					}()
+
+					for i := 0; i < 100; i++ {
+						entries := d.Snapshot()
+						Expect(len(entries)).To(BeNumerically("<=", 4))
+						for j := 1; j < len(entries); j++ {
+							Expect(entries[j].Seq).To(BeNumerically(">", entries[j-1].Seq))
+						}
+					}
+					wg.Wait()
+				})
+			})
+		})
+	}
+})
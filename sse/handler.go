@@ -0,0 +1,167 @@
+// Package sse streams the data of diodes to HTTP clients as Server-Sent
+// Events.
+package sse
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"code.cloudfoundry.org/go-diodes"
+)
+
+// DroppedEvent is the name of the event that is sent when the diode of a
+// request dropped data. Its data is the number of dropped values.
+const DroppedEvent = "dropped"
+
+// defaultHeartbeat is how often a heartbeat comment is sent by default.
+const defaultHeartbeat = 15 * time.Second
+
+// SubscribeFunc returns the Waiter that a request reads from. The alerter
+// has to be the alerter of the Waiter's diode so that drops are sent to the
+// client. The context is the request's context and is done when the client
+// disconnects. The returned unsubscribe func, if not nil, is invoked once the
+// request is done. Next has to return nil once the context is done or
+// unsubscribe was invoked.
+type SubscribeFunc func(ctx context.Context, alerter diodes.Alerter) (w *diodes.Waiter, unsubscribe func())
+
+// Encoder returns the data of the event for a value read from the diode.
+// The data may span several lines. If it returns an error the value is
+// skipped.
+type Encoder func(data diodes.GenericDataType) ([]byte, error)
+
+// Handler is an http.Handler that subscribes a Waiter for each request and
+// writes the values read from it as Server-Sent Events until the client
+// disconnects.
+type Handler struct {
+	subscribe SubscribeFunc
+	encode    Encoder
+	heartbeat time.Duration
+}
+
+// HandlerOption can be used to setup the handler.
+type HandlerOption func(*Handler)
+
+// WithHeartbeat sets how often a comment is sent while no values are read,
+// so that proxies do not close an idle connection. A duration of 0 or less
+// disables heartbeats. Default is 15 seconds.
+func WithHeartbeat(d time.Duration) HandlerOption {
+	return HandlerOption(func(h *Handler) {
+		h.heartbeat = d
+	})
+}
+
+// NewHandler returns a Handler that subscribes with the given func and
+// encodes each value with the given encoder.
+func NewHandler(subscribe SubscribeFunc, encode Encoder, opts ...HandlerOption) *Handler {
+	h := &Handler{
+		subscribe: subscribe,
+		encode:    encode,
+		heartbeat: defaultHeartbeat,
+	}
+
+	for _, o := range opts {
+		o(h)
+	}
+
+	return h
+}
+
+// event is either a value or a number of dropped values.
+type event struct {
+	data    diodes.GenericDataType
+	dropped int
+}
+
+// ServeHTTP implements http.Handler.
+func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	rc := http.NewResponseController(w)
+	ctx, cancel := context.WithCancel(r.Context())
+	defer cancel()
+
+	events := make(chan event)
+	send := func(e event) bool {
+		select {
+		case events <- e:
+			return true
+		case <-ctx.Done():
+			return false
+		}
+	}
+
+	waiter, unsubscribe := h.subscribe(ctx, diodes.AlertFunc(func(missed int) {
+		send(event{dropped: missed})
+	}))
+	if unsubscribe != nil {
+		defer unsubscribe()
+	}
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.WriteHeader(http.StatusOK)
+	if err := rc.Flush(); err != nil {
+		return
+	}
+
+	go func() {
+		for {
+			data := waiter.Next()
+			if data == nil || !send(event{data: data}) {
+				return
+			}
+		}
+	}()
+
+	var heartbeat <-chan time.Time
+	if h.heartbeat > 0 {
+		t := time.NewTicker(h.heartbeat)
+		defer t.Stop()
+		heartbeat = t.C
+	}
+
+	for {
+		var err error
+		select {
+		case <-ctx.Done():
+			return
+		case <-heartbeat:
+			_, err = io.WriteString(w, ": heartbeat\n\n")
+		case e := <-events:
+			err = h.write(w, e)
+		}
+
+		if err == nil {
+			err = rc.Flush()
+		}
+		if err != nil {
+			return
+		}
+	}
+}
+
+func (h *Handler) write(w io.Writer, e event) error {
+	if e.data == nil {
+		_, err := fmt.Fprintf(w, "event: %s\ndata: %d\n\n", DroppedEvent, e.dropped)
+		return err
+	}
+
+	data, err := h.encode(e.data)
+	if err != nil {
+		return nil
+	}
+
+	var buf bytes.Buffer
+	for _, line := range bytes.Split(data, []byte("\n")) {
+		buf.WriteString("data: ")
+		buf.Write(bytes.TrimSuffix(line, []byte("\r")))
+		buf.WriteByte('\n')
+	}
+	buf.WriteByte('\n')
+
+	_, err = w.Write(buf.Bytes())
+	return err
+}
@@ -0,0 +1,157 @@
+package sse_test
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"time"
+
+	"code.cloudfoundry.org/go-diodes"
+	"code.cloudfoundry.org/go-diodes/sse"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Handler", func() {
+	var (
+		hub    *diodes.Hub
+		opts   []sse.HandlerOption
+		server *httptest.Server
+		cancel context.CancelFunc
+		lines  chan string
+	)
+
+	encode := func(data diodes.GenericDataType) ([]byte, error) {
+		s := *(*string)(data)
+		if s == "bad" {
+			return nil, errors.New("bad value")
+		}
+		return []byte(s), nil
+	}
+
+	subscribe := func(_ context.Context, alerter diodes.Alerter) (*diodes.Waiter, func()) {
+		s := hub.Subscribe(16, alerter)
+		return s.Waiter, s.Unsubscribe
+	}
+
+	publish := func(s string) {
+		hub.Publish(diodes.GenericDataType(&s))
+	}
+
+	connect := func(h http.Handler) *http.Response {
+		server = httptest.NewServer(h)
+
+		var ctx context.Context
+		ctx, cancel = context.WithCancel(context.Background())
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, server.URL, nil)
+		Expect(err).ToNot(HaveOccurred())
+		resp, err := http.DefaultClient.Do(req)
+		Expect(err).ToNot(HaveOccurred())
+
+		// The scanner only uses its own channel, since lines is replaced by
+		// the next spec while it might still be running.
+		ch := make(chan string, 100)
+		lines = ch
+		go func() {
+			defer GinkgoRecover()
+			defer close(ch)
+			scanner := bufio.NewScanner(resp.Body)
+			for scanner.Scan() {
+				ch <- scanner.Text()
+			}
+		}()
+
+		return resp
+	}
+
+	// nextEvent returns the lines of the next event or comment.
+	nextEvent := func() []string {
+		var event []string
+		for {
+			var line string
+			Eventually(lines).Should(Receive(&line))
+			if line == "" {
+				return event
+			}
+			event = append(event, line)
+		}
+	}
+
+	BeforeEach(func() {
+		hub = diodes.NewHub()
+		opts = nil
+	})
+
+	AfterEach(func() {
+		cancel()
+		server.Close()
+	})
+
+	It("streams published values as events", func() {
+		resp := connect(sse.NewHandler(subscribe, encode, opts...))
+		Expect(resp.StatusCode).To(Equal(http.StatusOK))
+		Expect(resp.Header.Get("Content-Type")).To(Equal("text/event-stream"))
+		Eventually(hub.Subscribers).Should(Equal(1))
+
+		publish("hello")
+		publish("multi\nline")
+
+		Expect(nextEvent()).To(Equal([]string{"data: hello"}))
+		Expect(nextEvent()).To(Equal([]string{"data: multi", "data: line"}))
+	})
+
+	It("skips values that cannot be encoded", func() {
+		connect(sse.NewHandler(subscribe, encode, opts...))
+		Eventually(hub.Subscribers).Should(Equal(1))
+
+		publish("bad")
+		publish("good")
+
+		Expect(nextEvent()).To(Equal([]string{"data: good"}))
+	})
+
+	It("sends heartbeats", func() {
+		opts = append(opts, sse.WithHeartbeat(10*time.Millisecond))
+		connect(sse.NewHandler(subscribe, encode, opts...))
+
+		Expect(nextEvent()).To(Equal([]string{": heartbeat"}))
+	})
+
+	It("sends an event when values were dropped", func() {
+		connect(sse.NewHandler(func(ctx context.Context, alerter diodes.Alerter) (*diodes.Waiter, func()) {
+			w := diodes.NewWaiter(diodes.NewOneToOne(2, alerter), diodes.WithWaiterContext(ctx))
+			for _, s := range []string{"a", "b", "c"} {
+				w.Set(diodes.GenericDataType(&s))
+			}
+			return w, nil
+		}, encode, opts...))
+
+		Expect(nextEvent()).To(Equal([]string{"event: dropped", "data: 2"}))
+		Expect(nextEvent()).To(Equal([]string{"data: c"}))
+	})
+
+	It("unsubscribes when the client disconnects", func() {
+		connect(sse.NewHandler(subscribe, encode, opts...))
+		Eventually(hub.Subscribers).Should(Equal(1))
+
+		cancel()
+
+		Eventually(hub.Subscribers).Should(Equal(0))
+		Eventually(lines).Should(BeClosed())
+	})
+
+	It("stops reading when the client disconnects", func() {
+		stopped := make(chan struct{})
+		connect(sse.NewHandler(func(ctx context.Context, alerter diodes.Alerter) (*diodes.Waiter, func()) {
+			w := diodes.NewWaiter(diodes.NewManyToOne(16, alerter), diodes.WithWaiterContext(ctx))
+			return w, func() { close(stopped) }
+		}, encode, opts...))
+
+		cancel()
+
+		Eventually(stopped).Should(BeClosed())
+	})
+})
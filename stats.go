@@ -0,0 +1,85 @@
+package diodes
+
+// Stats is a point in time copy of a diode's counters. The counters are
+// updated with atomics and can be read from any go-routine.
+type Stats struct {
+	// Capacity is the size of the ring buffer.
+	Capacity int
+
+	// Len is the approximate number of values that have not been read yet.
+	Len int
+
+	// Writes is the number of values that were written. For a ManyToOne,
+	// the write indexes that writers skipped because of a collision are not
+	// included. The reader cannot tell a skipped index from a lost value,
+	// so Len and Dropped, or Reset, still count them.
+	Writes uint64
+
+	// Reads is the number of values that were read. It is derived from the
+	// read index, so while the reader is reading it may briefly include
+	// values that are about to be counted as Dropped or Expired.
+	Reads uint64
+
+	// Dropped is the number of values that were overwritten before they were
+	// read or discarded by Reset. Together with Expired, this is the total of
+	// what was reported to the alerter.
+	Dropped uint64
+
+	// Reset is the number of unread values discarded by Reset. They are
+	// included in Dropped.
+	Reset uint64
+
+	// Expired is the number of values that were skipped because they were
+	// older than the max age set with WithMaxAge.
+	Expired uint64
+
+	// Overwritten is the number of values that writers overwrote before
+	// they were read. Unlike Dropped, it is counted on Set and is therefore
+	// accurate even when the reader is blocked. It is only populated when
+	// WithWriterDropDetection is used.
+	Overwritten uint64
+
+	// Collisions is the number of times a ManyToOne writer had to retry
+	// because another writer was using the same slot.
+	Collisions uint64
+
+	// Latency holds the time values spent in the diode before they were
+	// read. It is only populated when WithLatencyHistogram is used.
+	Latency HistogramSnapshot
+
+	// TagDrops holds the number of dropped values per Tag. Tags without
+	// drops are left out. It is only populated when WithTagDrops is used.
+	TagDrops map[Tag]uint64
+}
+
+// StatsReporter is implemented by diodes that can report their Stats.
+type StatsReporter interface {
+	Stats() Stats
+}
+
+// addStats adds the counters of o to s.
+func addStats(s *Stats, o Stats) {
+	s.Capacity += o.Capacity
+	s.Len += o.Len
+	s.Writes += o.Writes
+	s.Reads += o.Reads
+	s.Dropped += o.Dropped
+	s.Reset += o.Reset
+	s.Expired += o.Expired
+	s.Overwritten += o.Overwritten
+	s.Collisions += o.Collisions
+	s.Latency = s.Latency.merge(o.Latency)
+
+	// The merged TagDrops are always a new map, so s never shares a map with
+	// the Stats it was copied from.
+	if len(o.TagDrops) > 0 {
+		tagDrops := make(map[Tag]uint64, len(s.TagDrops)+len(o.TagDrops))
+		for tag, n := range s.TagDrops {
+			tagDrops[tag] = n
+		}
+		for tag, n := range o.TagDrops {
+			tagDrops[tag] += n
+		}
+		s.TagDrops = tagDrops
+	}
+}
@@ -0,0 +1,123 @@
+package diodes_test
+
+import (
+	"time"
+
+	"code.cloudfoundry.org/go-diodes"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Stats", func() {
+	for _, storage := range storageTypes {
+		constructor := storage.constructor
+
+		Context(storage.name, func() {
+			var (
+				d     diodes.Diode
+				clock *fakeClock
+			)
+
+			BeforeEach(func() {
+				clock = newFakeClock()
+				d = constructor(4, nil, diodes.WithClock(clock))
+			})
+
+			stats := func() diodes.Stats {
+				return d.(diodes.StatsReporter).Stats()
+			}
+
+			It("starts empty", func() {
+				Expect(stats()).To(Equal(diodes.Stats{Capacity: 4}))
+			})
+
+			It("counts writes and reads", func() {
+				data := 1
+				for i := 0; i < 3; i++ {
+					d.Set(diodes.GenericDataType(&data))
+				}
+				d.TryNext()
+
+				s := stats()
+				Expect(s.Writes).To(Equal(uint64(3)))
+				Expect(s.Reads).To(Equal(uint64(1)))
+				Expect(s.Len).To(Equal(2))
+				Expect(s.Dropped).To(BeZero())
+			})
+
+			It("counts dropped values", func() {
+				data := 1
+				for i := 0; i < 6; i++ {
+					d.Set(diodes.GenericDataType(&data))
+				}
+				Expect(stats().Len).To(Equal(4))
+
+				d.TryNext()
+
+				s := stats()
+				Expect(s.Dropped).To(Equal(uint64(4)))
+				Expect(s.Reads).To(Equal(uint64(1)))
+				Expect(s.Len).To(Equal(1))
+			})
+
+			It("counts expired values", func() {
+				d = constructor(5, nil, diodes.WithClock(clock), diodes.WithMaxAge(time.Second))
+				data := 1
+				d.Set(diodes.GenericDataType(&data))
+				clock.Advance(2 * time.Second)
+				d.TryNext()
+
+				Expect(stats().Expired).To(Equal(uint64(1)))
+			})
+
+			It("does not record latencies by default", func() {
+				data := 1
+				d.Set(diodes.GenericDataType(&data))
+				d.TryNext()
+
+				Expect(stats().Latency.Count).To(BeZero())
+			})
+
+			It("records latencies with WithLatencyHistogram", func() {
+				d = constructor(5, nil, diodes.WithClock(clock), diodes.WithLatencyHistogram())
+				data := 1
+				d.Set(diodes.GenericDataType(&data))
+				d.Set(diodes.GenericDataType(&data))
+				clock.Advance(time.Millisecond)
+				d.TryNext()
+				d.TryNext()
+
+				latency := stats().Latency
+				Expect(latency.Count).To(Equal(uint64(2)))
+				Expect(latency.Sum).To(Equal(2 * time.Millisecond))
+				Expect(latency.Mean()).To(Equal(time.Millisecond))
+				Expect(latency.Quantile(0.99)).To(BeNumerically("~", time.Millisecond, time.Millisecond/8))
+			})
+		})
+	}
+
+	It("does not report collisions for a single ManyToOne writer", func() {
+		d := diodes.NewManyToOne(2, nil)
+		data := 1
+		for i := 0; i < 5; i++ {
+			d.Set(diodes.GenericDataType(&data))
+		}
+
+		Expect(d.Stats().Collisions).To(BeZero())
+	})
+
+	It("is reported by the Poller and Waiter", func() {
+		d := diodes.NewOneToOne(5, nil)
+		data := 1
+		d.Set(diodes.GenericDataType(&data))
+
+		Expect(diodes.NewPoller(d).Stats().Writes).To(Equal(uint64(1)))
+		Expect(diodes.NewWaiter(d).Stats().Writes).To(Equal(uint64(1)))
+	})
+
+	It("is empty for diodes without stats", func() {
+		Expect(diodes.NewPoller(new(spyDiode)).Stats()).To(Equal(diodes.Stats{}))
+		Expect(diodes.NewWaiter(new(spyDiode)).Stats()).To(Equal(diodes.Stats{}))
+	})
+})
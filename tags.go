@@ -0,0 +1,91 @@
+package diodes
+
+import (
+	"sync/atomic"
+)
+
+// Tag is a small integer that is stored with a value, such as an
+// application id or a source type. With WithTagDrops, dropped values are
+// counted per tag.
+type Tag uint8
+
+// TagAlerter is an Alerter that is also told the tags of dropped values.
+// When the alerter given to a diode with WithTagDrops implements TagAlerter,
+// AlertTag is invoked for every tag that lost values, in addition to Alert.
+type TagAlerter interface {
+	Alerter
+	AlertTag(tag Tag, missed int)
+}
+
+// TagAlertFunc type is an adapter to allow the use of ordinary functions as
+// TagAlerters. Its Alert ignores the totals and only AlertTag calls f.
+type TagAlertFunc func(tag Tag, missed int)
+
+// Alert does nothing. The missed values are reported per tag by AlertTag.
+func (f TagAlertFunc) Alert(int) {}
+
+// AlertTag calls f(tag, missed)
+func (f TagAlertFunc) AlertTag(tag Tag, missed int) {
+	f(tag, missed)
+}
+
+// tagCounts counts lost values per tag. A value is lost when a writer
+// replaces a bucket that was never read, or when the reader skips a bucket
+// because it is stale or expired. Since either the writer or the reader
+// removes a bucket from the ring, every lost value is counted exactly once.
+type tagCounts struct {
+	counts  [1 << 8]atomic.Uint64
+	pending atomic.Uint64
+
+	// alerted is only touched by the reader.
+	alerted [1 << 8]uint64
+}
+
+// lost counts the bucket as lost for its producer, if any, and for its tag
+// when tag drops are enabled.
+func (r *ring) lost(b *bucket) {
+	if p := b.producer(); p != nil {
+		r.lostProducer(p)
+	}
+
+	if r.tags == nil {
+		return
+	}
+
+	r.tags.counts[b.tag()].Add(1)
+	r.tags.pending.Add(1)
+}
+
+// alertTags reports the values lost since the last invocation to the
+// alerter, if it is a TagAlerter. It is invoked by the reader.
+func (r *ring) alertTags() {
+	if r.tags == nil || r.tags.pending.Load() == 0 {
+		return
+	}
+	r.tags.pending.Store(0)
+
+	a, ok := r.alerter.(TagAlerter)
+	for i := range r.tags.counts {
+		count := r.tags.counts[i].Load()
+		missed := count - r.tags.alerted[i]
+		if missed == 0 {
+			continue
+		}
+
+		r.tags.alerted[i] = count
+		if ok {
+			a.AlertTag(Tag(i), int(missed)) // nolint:gosec
+		}
+	}
+}
+
+func (t *tagCounts) snapshot() map[Tag]uint64 {
+	m := make(map[Tag]uint64)
+	for i := range t.counts {
+		if c := t.counts[i].Load(); c > 0 {
+			m[Tag(i)] = c
+		}
+	}
+
+	return m
+}
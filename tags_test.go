@@ -0,0 +1,97 @@
+package diodes_test
+
+import (
+	"time"
+
+	"code.cloudfoundry.org/go-diodes"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+type taggedDiode interface {
+	diodes.Diode
+	diodes.StatsReporter
+	SetTagged(tag diodes.Tag, data diodes.GenericDataType)
+}
+
+var _ = Describe("WithTagDrops", func() {
+	for _, storage := range storageTypes {
+		constructor := storage.constructor
+
+		Context(storage.name, func() {
+			type tagAlert struct {
+				tag    diodes.Tag
+				missed int
+			}
+
+			var (
+				alerts []tagAlert
+				clock  *fakeClock
+				d      taggedDiode
+			)
+
+			BeforeEach(func() {
+				alerts = nil
+				clock = newFakeClock()
+				d = constructor(2, diodes.TagAlertFunc(func(tag diodes.Tag, missed int) {
+					alerts = append(alerts, tagAlert{tag: tag, missed: missed})
+				}), diodes.WithTagDrops(), diodes.WithMaxAge(time.Minute), diodes.WithClock(clock)).(taggedDiode)
+			})
+
+			It("counts overwritten and skipped values per tag", func() {
+				data := 1
+				for i := 0; i < 3; i++ {
+					d.SetTagged(1, diodes.GenericDataType(&data))
+				}
+				for i := 0; i < 2; i++ {
+					d.SetTagged(2, diodes.GenericDataType(&data))
+				}
+
+				_, ok := d.TryNext()
+				Expect(ok).To(BeTrue())
+				Expect(alerts).To(Equal([]tagAlert{{tag: 1, missed: 3}}))
+
+				_, ok = d.TryNext()
+				Expect(ok).To(BeFalse())
+				Expect(alerts).To(Equal([]tagAlert{{tag: 1, missed: 3}, {tag: 2, missed: 1}}))
+
+				s := d.Stats()
+				Expect(s.TagDrops).To(Equal(map[diodes.Tag]uint64{1: 3, 2: 1}))
+				Expect(s.Dropped).To(Equal(uint64(4)))
+			})
+
+			It("counts expired values per tag", func() {
+				data := 1
+				d.SetTagged(3, diodes.GenericDataType(&data))
+				clock.Advance(2 * time.Minute)
+				d.Set(diodes.GenericDataType(&data))
+
+				_, ok := d.TryNext()
+				Expect(ok).To(BeTrue())
+				Expect(alerts).To(Equal([]tagAlert{{tag: 3, missed: 1}}))
+				Expect(d.Stats().TagDrops).To(Equal(map[diodes.Tag]uint64{3: 1}))
+			})
+
+			It("does not alert without drops", func() {
+				data := 1
+				d.SetTagged(1, diodes.GenericDataType(&data))
+				d.TryNext()
+
+				Expect(alerts).To(BeEmpty())
+				Expect(d.Stats().TagDrops).To(BeEmpty())
+			})
+		})
+	}
+
+	It("does not count per tag by default", func() {
+		d := diodes.NewOneToOne(2, nil)
+		data := 1
+		for i := 0; i < 5; i++ {
+			d.SetTagged(1, diodes.GenericDataType(&data))
+		}
+		d.TryNext()
+
+		Expect(d.Stats().TagDrops).To(BeNil())
+	})
+})
@@ -69,3 +69,35 @@ func (w *Waiter) Next() GenericDataType {
 		}
 	}
 }
+
+// NextSeq is like Next, but also returns the sequence number of the data. It
+// returns (nil, 0, false) if the context is done, and right away if the
+// wrapped diode does not implement SeqDiode.
+func (w *Waiter) NextSeq() (data GenericDataType, seq uint64, ok bool) {
+	d, ok := w.Diode.(SeqDiode)
+	if !ok {
+		return nil, 0, false
+	}
+
+	for {
+		data, seq, ok = d.TryNextSeq()
+		if ok {
+			return data, seq, true
+		}
+		select {
+		case <-w.ctx.Done():
+			return nil, 0, false
+		case <-w.c:
+		}
+	}
+}
+
+// Stats returns the Stats of the wrapped diode. If the wrapped diode does not
+// implement StatsReporter, the zero value is returned.
+func (w *Waiter) Stats() Stats {
+	if s, ok := w.Diode.(StatsReporter); ok {
+		return s.Stats()
+	}
+
+	return Stats{}
+}
@@ -82,4 +82,38 @@ var _ = Describe("Waiter", func() {
 			})
 		})
 	})
+
+	Describe("NextSeq", func() {
+		It("waits for data and returns its sequence number", func() {
+			w = diodes.NewWaiter(diodes.NewManyToOne(4, nil))
+			data := 1
+			w.Set(diodes.GenericDataType(&data))
+			w.Next()
+
+			go func() {
+				time.Sleep(10 * time.Millisecond)
+				w.Set(diodes.GenericDataType(&data))
+			}()
+
+			result, seq, ok := w.NextSeq()
+			Expect(ok).To(BeTrue())
+			Expect(*(*int)(result)).To(Equal(1))
+			Expect(seq).To(Equal(uint64(1)))
+		})
+
+		It("returns nil when the context is cancelled", func() {
+			ctx, cancel := context.WithCancel(context.Background())
+			cancel()
+			w = diodes.NewWaiter(diodes.NewOneToOne(4, nil), diodes.WithWaiterContext(ctx))
+
+			result, _, ok := w.NextSeq()
+			Expect(ok).To(BeFalse())
+			Expect(result == nil).To(BeTrue())
+		})
+
+		It("returns false if the diode does not report sequence numbers", func() {
+			_, _, ok := w.NextSeq()
+			Expect(ok).To(BeFalse())
+		})
+	})
 })
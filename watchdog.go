@@ -0,0 +1,136 @@
+package diodes
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// StallHandler is used to report that the reader of a diode has not made
+// progress for longer than the watchdog's timeout.
+type StallHandler interface {
+	Stall(stalled time.Duration)
+}
+
+// StallFunc type is an adapter to allow the use of ordinary functions as
+// StallHandlers.
+type StallFunc func(stalled time.Duration)
+
+// Stall calls f(stalled)
+func (f StallFunc) Stall(stalled time.Duration) {
+	f(stalled)
+}
+
+// Watchdog monitors the Stats of a diode from its own go-routine and reports
+// when the reader stops making progress while there is unread data. Unlike
+// the Alerter, which is invoked on the reader's go-routine and therefore only
+// fires once a hung reader wakes up, the StallHandler is invoked on the
+// watchdog's go-routine.
+type Watchdog struct {
+	d        StatsReporter
+	timeout  time.Duration
+	handler  StallHandler
+	interval time.Duration
+	ctx      context.Context
+	clock    Clock
+
+	mu       sync.Mutex
+	progress uint64
+	since    time.Time
+	reported bool
+}
+
+// WatchdogConfigOption can be used to setup the watchdog.
+type WatchdogConfigOption func(*Watchdog)
+
+// WithWatchdogInterval sets the interval at which the diode's Stats are
+// checked. The default is a quarter of the timeout.
+func WithWatchdogInterval(interval time.Duration) WatchdogConfigOption {
+	return WatchdogConfigOption(func(w *Watchdog) {
+		w.interval = interval
+	})
+}
+
+// WithWatchdogContext sets the context that stops Run. Default is
+// context.Background().
+func WithWatchdogContext(ctx context.Context) WatchdogConfigOption {
+	return WatchdogConfigOption(func(w *Watchdog) {
+		w.ctx = ctx
+	})
+}
+
+// WithWatchdogClock sets the clock used to measure how long the reader has
+// been stalled. The default is the system clock.
+func WithWatchdogClock(clock Clock) WatchdogConfigOption {
+	return WatchdogConfigOption(func(w *Watchdog) {
+		w.clock = clock
+	})
+}
+
+// NewWatchdog returns a new Watchdog for the given diode. The handler is
+// invoked once the reader has not advanced for the given timeout while there
+// is unread data. It is invoked once per stall: it fires again only after
+// the reader has made progress and stalled again.
+func NewWatchdog(d StatsReporter, timeout time.Duration, handler StallHandler, opts ...WatchdogConfigOption) *Watchdog {
+	w := &Watchdog{
+		d:        d,
+		timeout:  timeout,
+		handler:  handler,
+		interval: timeout / 4,
+		ctx:      context.Background(),
+		clock:    systemClock{},
+	}
+
+	for _, o := range opts {
+		o(w)
+	}
+
+	w.since = w.clock.Now()
+	return w
+}
+
+// Run checks the diode at every interval until the context is done. It is
+// meant to be invoked on its own go-routine.
+func (w *Watchdog) Run() {
+	t := time.NewTicker(w.interval)
+	defer t.Stop()
+
+	for {
+		select {
+		case <-w.ctx.Done():
+			return
+		case <-t.C:
+			w.Check()
+		}
+	}
+}
+
+// Check compares the reader's progress with the previous check and invokes
+// the StallHandler if the reader is stalled. Run invokes it at every
+// interval.
+func (w *Watchdog) Check() {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	s := w.d.Stats()
+	now := w.clock.Now()
+
+	// The read index advances for every value that is read, dropped or
+	// expired. When it moved, or when there is nothing left to read, the
+	// reader is not stalled.
+	progress := s.Reads + s.Dropped + s.Expired
+	if progress != w.progress || s.Len == 0 {
+		w.progress = progress
+		w.since = now
+		w.reported = false
+		return
+	}
+
+	stalled := now.Sub(w.since)
+	if stalled < w.timeout || w.reported {
+		return
+	}
+
+	w.reported = true
+	w.handler.Stall(stalled)
+}
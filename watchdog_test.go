@@ -0,0 +1,120 @@
+package diodes_test
+
+import (
+	"context"
+	"time"
+
+	"code.cloudfoundry.org/go-diodes"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Watchdog", func() {
+	var (
+		d       *diodes.ManyToOne
+		clock   *fakeClock
+		stalled chan time.Duration
+		w       *diodes.Watchdog
+	)
+
+	BeforeEach(func() {
+		d = diodes.NewManyToOne(5, nil)
+		clock = newFakeClock()
+		stalled = make(chan time.Duration, 100)
+		w = diodes.NewWatchdog(d, time.Minute, diodes.StallFunc(func(s time.Duration) {
+			stalled <- s
+		}), diodes.WithWatchdogClock(clock))
+	})
+
+	set := func() {
+		data := 1
+		d.Set(diodes.GenericDataType(&data))
+	}
+
+	It("does not fire while there is nothing to read", func() {
+		clock.Advance(time.Hour)
+		w.Check()
+
+		Expect(stalled).ToNot(Receive())
+	})
+
+	It("fires when the reader does not advance while there is unread data", func() {
+		set()
+		w.Check()
+		clock.Advance(30 * time.Second)
+		set()
+		w.Check()
+		Expect(stalled).ToNot(Receive())
+
+		clock.Advance(30 * time.Second)
+		set()
+		w.Check()
+		Expect(stalled).To(Receive(Equal(time.Minute)))
+	})
+
+	It("fires once per stall", func() {
+		set()
+		w.Check()
+		clock.Advance(time.Minute)
+		w.Check()
+		clock.Advance(time.Minute)
+		w.Check()
+
+		Expect(stalled).To(Receive())
+		Expect(stalled).ToNot(Receive())
+	})
+
+	It("does not fire while the reader advances", func() {
+		for i := 0; i < 5; i++ {
+			set()
+			set()
+			d.TryNext()
+			clock.Advance(30 * time.Second)
+			w.Check()
+		}
+
+		Expect(stalled).ToNot(Receive())
+	})
+
+	It("fires again after the reader recovers and stalls again", func() {
+		set()
+		w.Check()
+		clock.Advance(time.Minute)
+		w.Check()
+		Expect(stalled).To(Receive())
+
+		d.TryNext()
+		set()
+		w.Check()
+		clock.Advance(2 * time.Minute)
+		w.Check()
+		Expect(stalled).To(Receive(Equal(2 * time.Minute)))
+	})
+
+	It("checks the diode from its own go-routine until the context is done", func() {
+		ctx, cancel := context.WithCancel(context.Background())
+		w = diodes.NewWatchdog(d, time.Minute, diodes.StallFunc(func(s time.Duration) {
+			stalled <- s
+		}),
+			diodes.WithWatchdogClock(clock),
+			diodes.WithWatchdogInterval(time.Millisecond),
+			diodes.WithWatchdogContext(ctx),
+		)
+
+		done := make(chan struct{})
+		go func() {
+			defer close(done)
+			w.Run()
+		}()
+
+		set()
+		Eventually(func() <-chan time.Duration {
+			clock.Advance(time.Second)
+			return stalled
+		}).Should(Receive())
+
+		cancel()
+		Eventually(done).Should(BeClosed())
+	})
+})
@@ -0,0 +1,129 @@
+package diodes_test
+
+import (
+	"math"
+
+	"code.cloudfoundry.org/go-diodes"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+type wrappingDiode interface {
+	peekDiode
+	TryNextSeq() (diodes.GenericDataType, uint64, bool)
+	Len() int
+	Cap() int
+}
+
+var _ = Describe("Index wraparound", func() {
+	wrappingTypes := []struct {
+		name        string
+		constructor func(int, diodes.Alerter) wrappingDiode
+		setIndex    func(wrappingDiode, uint64)
+	}{
+		{
+			name: "OneToOne",
+			constructor: func(size int, a diodes.Alerter) wrappingDiode {
+				return diodes.NewOneToOne(size, a)
+			},
+			setIndex: func(d wrappingDiode, index uint64) {
+				diodes.SetOneToOneIndex(d.(*diodes.OneToOne), index)
+			},
+		},
+		{
+			name: "ManyToOne",
+			constructor: func(size int, a diodes.Alerter) wrappingDiode {
+				return diodes.NewManyToOne(size, a)
+			},
+			setIndex: func(d wrappingDiode, index uint64) {
+				diodes.SetManyToOneIndex(d.(*diodes.ManyToOne), index)
+			},
+		},
+	}
+
+	for _, storage := range wrappingTypes {
+		Context(storage.name, func() {
+			var (
+				spy *spyAlerter
+				d   wrappingDiode
+			)
+
+			set := func(values ...int) {
+				for _, v := range values {
+					d.Set(diodes.GenericDataType(&v))
+				}
+			}
+
+			next := func() (int, uint64) {
+				data, seq, ok := d.TryNextSeq()
+				Expect(ok).To(BeTrue())
+				return *(*int)(data), seq
+			}
+
+			BeforeEach(func() {
+				spy = newSpyAlerter()
+				d = storage.constructor(4, spy)
+				storage.setIndex(d, math.MaxUint64-1)
+			})
+
+			It("rounds the size up to a power of two", func() {
+				Expect(storage.constructor(5, nil).Cap()).To(Equal(8))
+				Expect(storage.constructor(8, nil).Cap()).To(Equal(8))
+				Expect(storage.constructor(1, nil).Cap()).To(Equal(1))
+			})
+
+			It("reads values in order across the overflow", func() {
+				for i := 0; i < 3; i++ {
+					set(2*i, 2*i+1)
+
+					v, seq := next()
+					Expect(v).To(Equal(2 * i))
+					Expect(seq).To(Equal(uint64(math.MaxUint64-1) + uint64(2*i)))
+
+					v, _ = next()
+					Expect(v).To(Equal(2*i + 1))
+				}
+
+				_, ok := d.TryNext()
+				Expect(ok).To(BeFalse())
+				Expect(spy.AlertCalled).ToNot(Receive())
+			})
+
+			It("fast forwards when the writer laps the reader across the overflow", func() {
+				set(0, 1, 2, 3, 4, 5)
+
+				v, seq := next()
+				Expect(v).To(Equal(4))
+				Expect(seq).To(Equal(uint64(2)))
+				Expect(spy.AlertInput.Missed).To(Receive(Equal(4)))
+
+				v, seq = next()
+				Expect(v).To(Equal(5))
+				Expect(seq).To(Equal(uint64(3)))
+
+				_, ok := d.TryNext()
+				Expect(ok).To(BeFalse())
+			})
+
+			It("reports Len, Peek and Snapshot across the overflow", func() {
+				set(0, 1, 2)
+				Expect(d.Len()).To(Equal(3))
+
+				data, ok := d.Peek()
+				Expect(ok).To(BeTrue())
+				Expect(*(*int)(data)).To(Equal(0))
+
+				var seqs []uint64
+				for _, e := range d.Snapshot() {
+					seqs = append(seqs, e.Seq)
+				}
+				Expect(seqs).To(Equal([]uint64{math.MaxUint64 - 1, math.MaxUint64, 0}))
+
+				next()
+				next()
+				Expect(d.Len()).To(Equal(1))
+			})
+		})
+	}
+})
@@ -0,0 +1,198 @@
+package diodes
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+var (
+	// ErrWriterClosed is returned when writing to a closed Writer.
+	ErrWriterClosed = errors.New("diodes: writer is closed")
+
+	// ErrFlushTimeout is returned when a Writer could not write everything
+	// to the underlying io.Writer before the timeout.
+	ErrFlushTimeout = errors.New("diodes: flush timed out")
+)
+
+// flushInterval is how often Flush checks whether the flusher caught up.
+const flushInterval = time.Millisecond
+
+// Writer is an io.Writer that copies each write into a ManyToOne diode and
+// returns immediately. A flusher go-routine writes the data to the
+// underlying io.Writer, so a slow sink never blocks the callers of Write.
+// When the flusher falls behind, data is dropped and reported to the
+// Writer's alerter.
+type Writer struct {
+	enqueued  atomic.Uint64
+	processed atomic.Uint64
+	closed    atomic.Bool
+
+	w       io.Writer
+	d       *Waiter
+	lines   bool
+	alerter Alerter
+	cancel  context.CancelFunc
+	done    chan struct{}
+
+	mu  sync.Mutex
+	err error
+}
+
+// WriterConfigOption can be used to setup the writer.
+type WriterConfigOption func(*Writer)
+
+// WithLineFraming splits each write into lines so that data is dropped a
+// whole line at a time. A newline is added to a write that does not end
+// with one.
+func WithLineFraming() WriterConfigOption {
+	return WriterConfigOption(func(w *Writer) {
+		w.lines = true
+	})
+}
+
+// WithWriterAlerter sets the alerter that is invoked on the flusher
+// go-routine when data is dropped. The missed count is the number of writes
+// (or lines with WithLineFraming) that were dropped.
+func WithWriterAlerter(alerter Alerter) WriterConfigOption {
+	return WriterConfigOption(func(w *Writer) {
+		w.alerter = alerter
+	})
+}
+
+// NewWriter returns a Writer that writes to w through a diode of the given
+// size. It starts a flusher go-routine that runs until Close is invoked.
+func NewWriter(w io.Writer, size int, opts ...WriterConfigOption) *Writer {
+	ctx, cancel := context.WithCancel(context.Background())
+	wr := &Writer{
+		w:      w,
+		cancel: cancel,
+		done:   make(chan struct{}),
+	}
+
+	for _, o := range opts {
+		o(wr)
+	}
+
+	if wr.alerter == nil {
+		wr.alerter = AlertFunc(func(int) {})
+	}
+
+	wr.d = NewWaiter(NewManyToOne(size, AlertFunc(wr.dropped)), WithWaiterContext(ctx))
+	go wr.flush()
+
+	return wr
+}
+
+// Write copies p into the diode and returns immediately. It only fails if
+// the Writer is closed. Errors from the underlying io.Writer are returned
+// by Flush and Close.
+func (w *Writer) Write(p []byte) (int, error) {
+	if w.closed.Load() {
+		return 0, ErrWriterClosed
+	}
+
+	if !w.lines {
+		w.set(bytes.Clone(p))
+		return len(p), nil
+	}
+
+	for rest := p; len(rest) > 0; {
+		i := bytes.IndexByte(rest, '\n')
+		if i < 0 {
+			line := make([]byte, len(rest)+1)
+			copy(line, rest)
+			line[len(rest)] = '\n'
+			w.set(line)
+			break
+		}
+
+		w.set(bytes.Clone(rest[:i+1]))
+		rest = rest[i+1:]
+	}
+
+	return len(p), nil
+}
+
+// Flush waits until everything written before it was invoked has been
+// written to the underlying io.Writer (or dropped). It returns
+// ErrFlushTimeout if that takes longer than the timeout, or else the last
+// error returned by the underlying io.Writer since the previous Flush.
+func (w *Writer) Flush(timeout time.Duration) error {
+	target := w.enqueued.Load()
+	deadline := time.Now().Add(timeout)
+
+	for w.processed.Load() < target {
+		if time.Now().After(deadline) {
+			return ErrFlushTimeout
+		}
+
+		time.Sleep(flushInterval)
+	}
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	err := w.err
+	w.err = nil
+	return err
+}
+
+// Close flushes the Writer and stops the flusher go-routine. Writes after
+// Close fail with ErrWriterClosed. It returns ErrFlushTimeout if the flusher
+// does not stop within the timeout, for example because the underlying
+// io.Writer blocks. The flusher then stops once that write returns. Close
+// does not close the underlying io.Writer.
+func (w *Writer) Close(timeout time.Duration) error {
+	deadline := time.Now().Add(timeout)
+	w.closed.Store(true)
+	err := w.Flush(timeout)
+
+	w.cancel()
+	t := time.NewTimer(time.Until(deadline))
+	defer t.Stop()
+
+	select {
+	case <-w.done:
+		return err
+	case <-t.C:
+		return ErrFlushTimeout
+	}
+}
+
+// Stats returns the Stats of the diode.
+func (w *Writer) Stats() Stats {
+	return w.d.Stats()
+}
+
+func (w *Writer) set(p []byte) {
+	w.enqueued.Add(1)
+	w.d.Set(GenericDataType(&p))
+}
+
+func (w *Writer) flush() {
+	defer close(w.done)
+
+	for {
+		data := w.d.Next()
+		if data == nil {
+			return
+		}
+
+		if _, err := w.w.Write(*(*[]byte)(data)); err != nil {
+			w.mu.Lock()
+			w.err = err
+			w.mu.Unlock()
+		}
+		w.processed.Add(1)
+	}
+}
+
+// dropped is the diode's alerter. It runs on the flusher go-routine.
+func (w *Writer) dropped(missed int) {
+	w.processed.Add(uint64(missed)) // nolint:gosec
+	w.alerter.Alert(missed)
+}
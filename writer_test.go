@@ -0,0 +1,211 @@
+package diodes_test
+
+import (
+	"bytes"
+	"errors"
+	"sync"
+	"time"
+
+	"code.cloudfoundry.org/go-diodes"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Writer", func() {
+	var (
+		sink *spyWriter
+		w    *diodes.Writer
+	)
+
+	BeforeEach(func() {
+		sink = newSpyWriter()
+		w = diodes.NewWriter(sink, 8)
+	})
+
+	AfterEach(func() {
+		sink.Unblock()
+		w.Close(time.Second) //nolint:errcheck
+	})
+
+	It("writes to the underlying writer", func() {
+		n, err := w.Write([]byte("hello "))
+		Expect(err).ToNot(HaveOccurred())
+		Expect(n).To(Equal(6))
+		w.Write([]byte("world")) //nolint:errcheck
+
+		Expect(w.Flush(time.Second)).To(Succeed())
+		Expect(sink.String()).To(Equal("hello world"))
+	})
+
+	It("copies the written data", func() {
+		p := []byte("abc")
+		w.Write(p) //nolint:errcheck
+		copy(p, "xyz")
+
+		Expect(w.Flush(time.Second)).To(Succeed())
+		Expect(sink.String()).To(Equal("abc"))
+	})
+
+	It("does not block on a slow writer", func() {
+		sink.Block()
+		done := make(chan struct{})
+		go func() {
+			defer close(done)
+			for i := 0; i < 100; i++ {
+				w.Write([]byte("x")) //nolint:errcheck
+			}
+		}()
+
+		Eventually(done).Should(BeClosed())
+	})
+
+	It("reports dropped writes", func() {
+		spy := newSpyAlerter()
+		w = diodes.NewWriter(sink, 4, diodes.WithWriterAlerter(spy))
+
+		sink.Block()
+		w.Write([]byte("first")) //nolint:errcheck
+		Eventually(sink.Blocked).Should(BeTrue())
+		for i := 0; i < 10; i++ {
+			w.Write([]byte("x")) //nolint:errcheck
+		}
+		sink.Unblock()
+
+		Expect(w.Flush(time.Second)).To(Succeed())
+		Expect(spy.AlertInput.Missed).To(Receive(Equal(8)))
+		Expect(sink.String()).To(Equal("firstxx"))
+	})
+
+	It("times out when the writer does not catch up", func() {
+		sink.Block()
+		w.Write([]byte("x")) //nolint:errcheck
+
+		Expect(w.Flush(10 * time.Millisecond)).To(MatchError(diodes.ErrFlushTimeout))
+	})
+
+	It("does not wait past the timeout when closing a blocked writer", func() {
+		sink.Block()
+		w.Write([]byte("x")) //nolint:errcheck
+		Eventually(sink.Blocked).Should(BeTrue())
+
+		errs := make(chan error, 1)
+		go func() {
+			errs <- w.Close(10 * time.Millisecond)
+		}()
+
+		Eventually(errs, 500*time.Millisecond).Should(Receive(MatchError(diodes.ErrFlushTimeout)))
+	})
+
+	It("returns errors from the underlying writer when flushing", func() {
+		sink.SetErr(errors.New("broken pipe"))
+		w.Write([]byte("x")) //nolint:errcheck
+
+		Expect(w.Flush(time.Second)).To(MatchError("broken pipe"))
+		Expect(w.Flush(time.Second)).To(Succeed())
+	})
+
+	It("flushes and rejects writes when it is closed", func() {
+		w.Write([]byte("x")) //nolint:errcheck
+		Expect(w.Close(time.Second)).To(Succeed())
+		Expect(sink.String()).To(Equal("x"))
+
+		_, err := w.Write([]byte("y"))
+		Expect(err).To(MatchError(diodes.ErrWriterClosed))
+	})
+
+	Context("with line framing", func() {
+		BeforeEach(func() {
+			w = diodes.NewWriter(sink, 8, diodes.WithLineFraming())
+		})
+
+		It("splits writes into lines and terminates the last one", func() {
+			n, err := w.Write([]byte("a\nb\nc"))
+			Expect(err).ToNot(HaveOccurred())
+			Expect(n).To(Equal(5))
+
+			Expect(w.Flush(time.Second)).To(Succeed())
+			Expect(sink.Writes()).To(Equal([]string{"a\n", "b\n", "c\n"}))
+		})
+
+		It("does not add a newline to terminated writes", func() {
+			w.Write([]byte("a\n")) //nolint:errcheck
+
+			Expect(w.Flush(time.Second)).To(Succeed())
+			Expect(sink.Writes()).To(Equal([]string{"a\n"}))
+		})
+	})
+})
+
+// spyWriter records what is written to it. It can be blocked to simulate a
+// slow sink.
+type spyWriter struct {
+	mu      sync.Mutex
+	buf     bytes.Buffer
+	writes  []string
+	err     error
+	block   chan struct{}
+	blocked bool
+}
+
+func newSpyWriter() *spyWriter {
+	return &spyWriter{}
+}
+
+func (s *spyWriter) Write(p []byte) (int, error) {
+	s.mu.Lock()
+	block := s.block
+	s.blocked = block != nil
+	s.mu.Unlock()
+	if block != nil {
+		<-block
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.blocked = false
+	if s.err != nil {
+		return 0, s.err
+	}
+	s.writes = append(s.writes, string(p))
+	return s.buf.Write(p)
+}
+
+func (s *spyWriter) String() string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.buf.String()
+}
+
+func (s *spyWriter) Writes() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]string{}, s.writes...)
+}
+
+func (s *spyWriter) SetErr(err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.err = err
+}
+
+func (s *spyWriter) Block() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.block = make(chan struct{})
+}
+
+func (s *spyWriter) Blocked() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.blocked
+}
+
+func (s *spyWriter) Unblock() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.block != nil {
+		close(s.block)
+		s.block = nil
+	}
+}